@@ -0,0 +1,139 @@
+package gas
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// Assets caches fonts, sounds, music and images for any number of Views, so a second window
+// (eg. a debug inspector) shows the same assets as the game without loading them again.
+// Fonts, sounds and music are global. Images decode once and upload to each renderer as a Texture.
+// Textures re-upload from the decoded image if a renderer loses them (see TexturesReload).
+type Assets struct {
+	fonts    map[string]*ttf.Font
+	images   map[string]*sdl.Surface // decoded images by path
+	mu       sync.Mutex              // views may load from other goroutines
+	music    map[string]*Music
+	sounds   map[string]*Wav
+	textures map[*sdl.Renderer]map[string]*Texture // uploaded images by renderer and path
+}
+
+// AssetsShared is the cache for views that do not set their own
+var AssetsShared = MakeAssets()
+
+// MakeAssets returns an empty cache
+func MakeAssets() *Assets {
+	return &Assets{
+		fonts:    make(map[string]*ttf.Font),
+		images:   make(map[string]*sdl.Surface),
+		music:    make(map[string]*Music),
+		sounds:   make(map[string]*Wav),
+		textures: make(map[*sdl.Renderer]map[string]*Texture),
+	}
+}
+
+// FontLoad returns the font at path in size pts, opening it on first use
+func (a *Assets) FontLoad(path string, size int) (font *ttf.Font, err error) {
+	key := fmt.Sprintf("%s-%d", path, size)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	font, ok := a.fonts[key]
+	if !ok {
+		font, err = ttf.OpenFont(path, size)
+		if err != nil {
+			return nil, assetErr("font", path, err)
+		}
+		a.fonts[key] = font
+	}
+	return font, nil
+}
+
+// SoundLoad returns the sound at path, loading it on first use through the backend of AudioShared
+func (a *Assets) SoundLoad(path string) (*Wav, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	snd, ok := a.sounds[path]
+	if !ok {
+		var err error
+		snd, err = audioBackend().SoundLoad(path)
+		if err != nil {
+			return nil, assetErr("sound", path, err)
+		}
+		a.sounds[path] = snd
+	}
+	return snd, nil
+}
+
+// TextureLoad returns the image at path as a texture for renderer r.
+// Decodes the image on first use and uploads it to each renderer on first use there.
+func (a *Assets) TextureLoad(r *sdl.Renderer, path string) (*Texture, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	textures, ok := a.textures[r]
+	if !ok {
+		textures = make(map[string]*Texture)
+		a.textures[r] = textures
+	}
+	if texture, ok := textures[path]; ok {
+		return texture, nil
+	}
+
+	image, ok := a.images[path]
+	if !ok {
+		var err error
+		image, err = img.Load(path)
+		if err != nil {
+			return nil, assetErr("texture", path, err)
+		}
+		a.images[path] = image
+	}
+	texture := &Texture{H: image.H, W: image.W, image: image, path: path}
+	if err := texture.upload(r); err != nil {
+		return nil, err
+	}
+	textures[path] = texture
+	return texture, nil
+}
+
+// TexturesReload uploads the images for r again, keeping the Textures, so dobs
+// paint as before. Directors call it when sdl reports that the renderer lost its textures.
+func (a *Assets) TexturesReload(r *sdl.Renderer) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, texture := range a.textures[r] {
+		if texture.SDLTexture != nil {
+			texture.SDLTexture.Destroy()
+		}
+		if err := texture.upload(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TexturesRm destroys the textures for r. Views call it on Destroy.
+func (a *Assets) TexturesRm(r *sdl.Renderer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, texture := range a.textures[r] {
+		if texture.SDLTexture != nil {
+			texture.SDLTexture.Destroy()
+			texture.SDLTexture = nil
+		}
+	}
+	delete(a.textures, r)
+}
+
+// upload makes the texture on r from the decoded image
+func (t *Texture) upload(r *sdl.Renderer) (err error) {
+	t.SDLTexture, err = r.CreateTextureFromSurface(t.image)
+	t.blend = BlendAlpha
+	if err != nil {
+		return fmt.Errorf("could not upload texture at %s: %v", t.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,452 @@
+package gas
+
+import (
+	"sync"
+	"time"
+)
+
+// AudioChannels is how many sounds play at once, across all groups
+const AudioChannels = 16
+
+// AudioShared plays the sounds of views that do not set their own Audio.
+// Init opens it on the sdl mixer, or on a NullBackend if there is no audio device.
+var AudioShared *Audio
+
+// Audio mixes music and sound effects. Volumes run from 0 to 1 and multiply, so a sound
+// plays at Master * the Volume of its group * its own Volume, and music at Master * Music.
+// Audio applies volumes and calls completion callbacks once per tick. Directors tick it.
+// An AudioBackend does the playing.
+type Audio struct {
+	Backend  AudioBackend
+	DuckTime time.Duration // time for music to duck and recover
+	DuckTo   float32       // music volume while sounds of a Duck group play. defaults to .4
+	Master   float32
+	Music    float32 // music volume
+	Pan      float32 // how far sounds pan with the X of their dob on screen, from 0 for none to 1 for hard left and right. defaults to .8
+	channels []channel
+	duck     float32 // current ducking of the music
+	fade     float32 // current fade of the music. see MusicFade
+	finished []int   // channels the backend reported done, pending delivery on the game thread
+	groups   map[string]*SoundGroup
+	mu       sync.Mutex // guards finished, which the mixer writes from the audio thread
+	music    *Music     // playing music or nil
+	musicVol float32    // music volume last applied to the backend
+	seq      uint64     // counts plays, to find the oldest
+}
+
+// channel is a backend channel playing a sound
+type channel struct {
+	group  *SoundGroup
+	onDone func()
+	pan    float32 // panning last applied to the backend. outlives the sound, as the mixer keeps it
+	seq    uint64  // play order
+	vol    float32 // volume last applied to the backend
+	wav    *Wav    // playing sound or nil
+}
+
+// SoundGroup is a set of sounds with a shared volume and limit, eg. "sfx", "ui" or "voice"
+type SoundGroup struct {
+	Duck   bool // music ducks while sounds of the group play
+	Max    int  // the most sounds of the group to play at once. the oldest stops for a new one. 0 for no limit
+	Name   string
+	Volume float32
+	audio  *Audio
+}
+
+// MakeAudio returns the audio service for backend b. Init calls it for AudioShared.
+func MakeAudio(b AudioBackend) *Audio {
+	a := &Audio{Backend: b, DuckTime: 250 * time.Millisecond, DuckTo: .4, Master: 1, Music: 1, Pan: .8, duck: 1, fade: 1, musicVol: -1}
+	a.groups = make(map[string]*SoundGroup)
+	a.channels = make([]channel, b.Channels())
+	for i := range a.channels {
+		a.channels[i].vol = -1
+	}
+	b.Finished(func(ch int) {
+		// maybe the audio thread. deliver on the next tick
+		a.mu.Lock()
+		a.finished = append(a.finished, ch)
+		a.mu.Unlock()
+	})
+	return a
+}
+
+// Group returns the sound group called name, making it on first use.
+// Wav.Play plays in the "sfx" group.
+func (a *Audio) Group(name string) *SoundGroup {
+	g, ok := a.groups[name]
+	if !ok {
+		g = &SoundGroup{Name: name, Volume: 1, audio: a}
+		a.groups[name] = g
+	}
+	return g
+}
+
+// Play plays w once in the group and calls onDone, if not nil, on the tick it finishes or stops.
+// Returns the channel, which Stop takes.
+func (g *SoundGroup) Play(w *Wav, onDone func()) (int, error) {
+	return g.play(w, 0, onDone)
+}
+
+// play implements Play, panning the sound by pan
+func (g *SoundGroup) play(w *Wav, pan float32, onDone func()) (int, error) {
+	a := g.audio
+	// channels the backend finished are free for reuse, so settle them first
+	a.finishedDeliver()
+	if g.Max > 0 {
+		n, oldest := 0, -1
+		for i := range a.channels {
+			if c := &a.channels[i]; c.wav != nil && c.group == g {
+				n++
+				if oldest < 0 || c.seq < a.channels[oldest].seq {
+					oldest = i
+				}
+			}
+		}
+		if n >= g.Max {
+			a.Stop(oldest)
+		}
+	}
+
+	vol := a.Master * g.Volume * w.Volume
+	ch, err := a.Backend.Play(w, -1, vol, pan)
+	if err != nil {
+		// every channel is busy. steal the oldest
+		oldest := -1
+		for i := range a.channels {
+			if c := &a.channels[i]; c.wav != nil && (oldest < 0 || c.seq < a.channels[oldest].seq) {
+				oldest = i
+			}
+		}
+		if oldest < 0 {
+			return -1, err
+		}
+		a.Stop(oldest)
+		if ch, err = a.Backend.Play(w, oldest, vol, pan); err != nil {
+			return -1, err
+		}
+	}
+	if ch >= len(a.channels) {
+		// the backend has more channels than it said
+		a.channels = append(a.channels, make([]channel, ch+1-len(a.channels))...)
+	}
+	a.seq++
+	c := &a.channels[ch]
+	// the sound last on ch may have finished on the audio thread after finishedDeliver above.
+	// the backend reused ch, so take its report now, before it can end the new sound
+	var staleDone func()
+	if c.wav != nil && a.finishedTake(ch) {
+		staleDone = c.onDone
+	}
+	*c = channel{group: g, onDone: onDone, pan: pan, seq: a.seq, vol: vol, wav: w}
+	if staleDone != nil {
+		staleDone()
+	}
+	return ch, nil
+}
+
+// Stop stops the sounds of the group
+func (g *SoundGroup) Stop() {
+	a := g.audio
+	for i := range a.channels {
+		if a.channels[i].group == g && a.channels[i].wav != nil {
+			a.Stop(i)
+		}
+	}
+}
+
+// Stop stops the sound on channel ch and calls its onDone
+func (a *Audio) Stop(ch int) {
+	if ch < 0 || ch >= len(a.channels) || a.channels[ch].wav == nil {
+		return
+	}
+	// the backend reports the halt through Finished
+	a.Backend.Stop(ch)
+	a.finishedDeliver()
+}
+
+// PanSet pans the sound on channel ch from -1 for left to 1 for right
+func (a *Audio) PanSet(ch int, pan float32) {
+	if ch < 0 || ch >= len(a.channels) || a.channels[ch].pan == pan {
+		return
+	}
+	a.channels[ch].pan = pan
+	a.Backend.Pan(ch, pan)
+}
+
+// Playing returns the number of sounds playing
+func (a *Audio) Playing() (n int) {
+	for i := range a.channels {
+		if a.channels[i].wav != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// MusicPlay streams m, looping it loops times or forever if loops is -1, and stops any other music.
+// Use MusicFade to fade or crossfade.
+func (a *Audio) MusicPlay(m *Music, loops int) error {
+	a.music = m
+	a.musicVol = a.musicVolumeOf(m)
+	return a.Backend.MusicPlay(m, loops, a.musicVol)
+}
+
+// MusicStop stops the music
+func (a *Audio) MusicStop() {
+	a.music = nil
+	a.Backend.MusicStop()
+}
+
+// Tick delivers completion callbacks and applies volumes and ducking.
+// durationPerTick paces the ducking.
+func (a *Audio) Tick(tick int32, durationPerTick int64) {
+	a.Backend.Tick(tick)
+	a.finishedDeliver()
+
+	duck := float32(1)
+	for i := range a.channels {
+		if c := &a.channels[i]; c.wav != nil {
+			if c.group.Duck {
+				duck = a.DuckTo
+			}
+			a.channelVolume(c, i)
+		}
+	}
+	// ease toward the duck volume
+	step := float32(1)
+	if a.DuckTime > 0 {
+		step = float32(durationPerTick) / float32(a.DuckTime)
+	}
+	switch {
+	case a.duck > duck+step:
+		a.duck -= step
+	case a.duck < duck-step:
+		a.duck += step
+	default:
+		a.duck = duck
+	}
+
+	if a.music != nil && !a.Backend.MusicPlaying() {
+		a.music = nil
+	}
+	a.musicVolume()
+}
+
+// finishedDeliver frees the channels the mixer finished and calls their onDone
+func (a *Audio) finishedDeliver() {
+	a.mu.Lock()
+	finished := a.finished
+	a.finished = nil
+	a.mu.Unlock()
+	for _, ch := range finished {
+		if ch >= len(a.channels) {
+			continue
+		}
+		c := &a.channels[ch]
+		onDone := c.onDone
+		*c = channel{pan: c.pan, vol: -1}
+		if onDone != nil {
+			onDone()
+		}
+	}
+}
+
+// finishedTake removes a pending report for ch. Returns false if there is none
+func (a *Audio) finishedTake(ch int) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, f := range a.finished {
+		if f == ch {
+			a.finished = append(a.finished[:i], a.finished[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// channelVolume applies the volume for channel c, numbered ch, if it changed
+func (a *Audio) channelVolume(c *channel, ch int) {
+	vol := a.Master * c.group.Volume * c.wav.Volume
+	if vol != c.vol {
+		a.Backend.Volume(ch, vol)
+		c.vol = vol
+	}
+}
+
+// musicVolume applies the music volume if it changed
+func (a *Audio) musicVolume() {
+	if vol := a.musicVolumeOf(a.music); vol != a.musicVol {
+		a.Backend.MusicVolume(vol)
+		a.musicVol = vol
+	}
+}
+
+// musicVolumeOf returns the volume for music m, or 0 if nil
+func (a *Audio) musicVolumeOf(m *Music) float32 {
+	if m == nil {
+		return 0
+	}
+	return a.Master * a.Music * a.duck * a.fade * m.Volume
+}
+
+// MusicLoad returns the music at path, opening it on first use through the backend of AudioShared.
+// Music streams, so it stays open until the program exits.
+func (a *Assets) MusicLoad(path string) (*Music, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	m, ok := a.music[path]
+	if !ok {
+		var err error
+		m, err = audioBackend().MusicLoad(path)
+		if err != nil {
+			return nil, assetErr("music", path, err)
+		}
+		a.music[path] = m
+	}
+	return m, nil
+}
+
+// VolumeAn tweens a volume, eg. Audio.Master, Audio.Music or SoundGroup.Volume
+type VolumeAn struct {
+	BaseAn
+	delta float32
+	dst   float32
+	vol   *float32
+}
+
+// VolumeTo yields a VolumeAn for vol. eg. a.VolumeTo(&audio.Master, 0, time.Second, nil) fades out everything.
+func (a *BaseAn) VolumeTo(vol *float32, dst float32, duration time.Duration, easer Ease) *VolumeAn {
+	anID++
+	if easer == nil {
+		easer = EaseNone
+	}
+	b := &VolumeAn{BaseAn: BaseAn{id: anID, dob: a.dob, Duration: int64(duration), Easer: easer}, dst: dst, vol: vol}
+	return a.AnSetAdd(b).(*VolumeAn)
+}
+
+func (a *VolumeAn) Tick(tick int32) bool {
+	if a.StartTick == 0 {
+		a.StartTick = tick
+		a.delta = a.dst - *a.vol
+	}
+	pct, eased := a.PC(tick)
+	*a.vol = a.dst - a.delta + eased*a.delta
+	return pct == 1
+}
+
+// MusicAn fades the music out and the next music in
+type MusicAn struct {
+	BaseAn
+	audio    *Audio
+	from     float32 // fade at the start
+	loops    int
+	next     *Music
+	split    float32 // percent complete when the next music starts
+	switched bool
+}
+
+// MusicFade yields a MusicAn that crossfades from the music playing to m, looping it loops times
+// or forever if loops is -1. A nil m fades the music out. With no music playing, m fades in.
+// The mixer streams one music at a time, so the playing music fades out over the first half
+// and m fades in over the second.
+func (a *BaseAn) MusicFade(m *Music, loops int, duration time.Duration) *MusicAn {
+	anID++
+	b := &MusicAn{BaseAn: BaseAn{id: anID, dob: a.dob, Duration: int64(duration), Easer: EaseInOutSin}, audio: a.dob.Stage.view.Audio, loops: loops, next: m}
+	return a.AnSetAdd(b).(*MusicAn)
+}
+
+func (a *MusicAn) Tick(tick int32) bool {
+	au := a.audio
+	if a.StartTick == 0 {
+		a.StartTick = tick
+		a.from = au.fade
+		switch {
+		case au.music == nil:
+			a.split = 0
+		case a.next == nil:
+			a.split = 1
+		default:
+			a.split = .5
+		}
+	}
+	pct, _ := a.PC(tick)
+	if pct < a.split {
+		au.fade = a.from * (1 - a.Easer(pct/a.split))
+	} else {
+		if !a.switched {
+			a.switched = true
+			au.fade = 0
+			if a.next == nil {
+				au.MusicStop()
+				au.fade = 1
+			} else {
+				au.MusicPlay(a.next, a.loops)
+			}
+		}
+		if a.next != nil {
+			au.fade = a.Easer((pct - a.split) / (1 - a.split))
+		}
+	}
+	au.musicVolume()
+	return pct == 1
+}
+
+// Play is sugar for PlaySound. The sound plays when the An before it completes
+func (a *BaseAn) Play(w *Wav) *SoundAn {
+	return a.PlaySound(w)
+}
+
+// SoundAn plays a sound, panned by the X of the dob on screen.
+// It completes at once, so chained Ans start on the same tick.
+// eg. dob.MoveTo(...).PlaySound(hop).MoveTo(...)
+type SoundAn struct {
+	BaseAn
+	Group *SoundGroup // defaults to "sfx"
+	wav   *Wav
+}
+
+// PlaySound yields a SoundAn for BaseAn.Dob
+func (a *BaseAn) PlaySound(w *Wav) *SoundAn {
+	anID++
+	b := anGet(a.dob.Stage.Pool, poolSounds)
+	b.BaseAn = BaseAn{id: anID, dob: a.dob, anSet: b.anSet, Duration: 0, Easer: nil}
+	b.wav = w
+	return a.AnSetAdd(b).(*SoundAn)
+}
+
+func (a *SoundAn) Tick(tick int32) bool {
+	a.dob.soundPlay(a.wav, a.Group)
+	return true
+}
+
+// soundPlay plays w in group g, or "sfx" if nil, panned by the X of the dob on screen
+func (d *Dob) soundPlay(w *Wav, g *SoundGroup) {
+	audio := d.Stage.view.Audio
+	if audio == nil || w == nil {
+		return
+	}
+	if g == nil {
+		g = audio.Group("sfx")
+	}
+	g.play(w, audio.Pan*d.pan(), nil)
+}
+
+// pan returns the X of the dob on screen from -1 at the left edge to 1 at the right.
+// Maps its position through the camera it last painted with, if any.
+func (d *Dob) pan() float32 {
+	x, y := d.center()
+	if d.painted.zoom != 0 {
+		x, _ = d.painted.xf.apply(x, y)
+	}
+	w := float32(d.Stage.view.W)
+	if w <= 0 {
+		return 0
+	}
+	pan := 2*x/w - 1
+	if pan < -1 {
+		return -1
+	} else if pan > 1 {
+		return 1
+	}
+	return pan
+}
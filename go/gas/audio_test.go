@@ -0,0 +1,298 @@
+package gas
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// holdBackend is a NullBackend whose sounds play until they stop, rather than for one tick
+type holdBackend struct {
+	*NullBackend
+}
+
+func (b holdBackend) Tick(tick int32) {}
+
+// audioTest returns an Audio over a RecordBackend with n channels whose sounds play until stopped
+func audioTest(n int) (*Audio, *RecordBackend) {
+	b := MakeRecordBackend(holdBackend{&NullBackend{busy: make([]bool, n)}})
+	return MakeAudio(b), b
+}
+
+// eventsCheck compares the log of b to want, with some slack for float volumes and pans
+func eventsCheck(t *testing.T, b *RecordBackend, want []AudioEvent) {
+	t.Helper()
+	near := func(a, b float32) bool { return math.Abs(float64(a-b)) < 1e-4 }
+	ok := len(b.Events) == len(want)
+	for i := 0; ok && i < len(want); i++ {
+		g, w := b.Events[i], want[i]
+		ok = g.Music == w.Music && g.Sound == w.Sound && g.Tick == w.Tick && near(g.Volume, w.Volume) && near(g.Pan, w.Pan)
+	}
+	if !ok {
+		t.Errorf("events\n got %+v\nwant %+v", b.Events, want)
+	}
+}
+
+func TestAudioPlay(t *testing.T) {
+	hop, splash, croak := &Wav{Volume: 1, path: "hop"}, &Wav{Volume: .8, path: "splash"}, &Wav{Volume: 1, path: "croak"}
+	tests := []struct {
+		name     string
+		channels int
+		play     func(t *testing.T, a *Audio) (done int)
+		playing  int
+		done     int // onDone calls
+		want     []AudioEvent
+	}{
+		{
+			name:     "volume",
+			channels: 4,
+			play: func(t *testing.T, a *Audio) int {
+				a.Master = .5
+				a.Group("sfx").Volume = .5
+				a.Group("sfx").Play(splash, nil)
+				return 0
+			},
+			playing: 1,
+			want:    []AudioEvent{{Sound: "splash", Tick: 1, Volume: .2}},
+		},
+		{
+			name:     "group max stops the oldest of the group",
+			channels: 4,
+			play: func(t *testing.T, a *Audio) (done int) {
+				ui := a.Group("ui")
+				ui.Max = 2
+				a.Group("sfx").Play(croak, nil)
+				for _, w := range []*Wav{hop, hop, hop} {
+					ui.Play(w, func() { done++ })
+				}
+				return
+			},
+			playing: 3,
+			done:    1,
+			want: []AudioEvent{
+				{Sound: "croak", Tick: 1, Volume: 1},
+				{Sound: "hop", Tick: 1, Volume: 1},
+				{Sound: "hop", Tick: 1, Volume: 1},
+				{Sound: "hop", Tick: 1, Volume: 1},
+			},
+		},
+		{
+			name:     "full channels steal the oldest",
+			channels: 2,
+			play: func(t *testing.T, a *Audio) (done int) {
+				a.Group("sfx").Play(croak, func() { done++ })
+				a.Group("ui").Play(hop, nil)
+				if ch, _ := a.Group("sfx").Play(splash, nil); ch != 0 {
+					t.Errorf("splash played on channel %d, want 0 of croak", ch)
+				}
+				return
+			},
+			playing: 2,
+			done:    1,
+			want: []AudioEvent{
+				{Sound: "croak", Tick: 1, Volume: 1},
+				{Sound: "hop", Tick: 1, Volume: 1},
+				{Sound: "splash", Tick: 1, Volume: .8},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := audioTest(tt.channels)
+			a.Tick(1, int64(time.Second/30))
+			if done := tt.play(t, a); done != tt.done {
+				t.Errorf("onDone ran %d times, want %d", done, tt.done)
+			}
+			if n := a.Playing(); n != tt.playing {
+				t.Errorf("%d playing, want %d", n, tt.playing)
+			}
+			eventsCheck(t, b, tt.want)
+		})
+	}
+}
+
+func TestAudioMusic(t *testing.T) {
+	dpt := int64(time.Second / 30)
+	tests := []struct {
+		name  string
+		run   func(a *Audio, s *Stage, tick func(n int))
+		vol   float32 // music volume applied at the end
+		music []string
+	}{
+		{
+			name: "duck while a duck group plays",
+			run: func(a *Audio, s *Stage, tick func(n int)) {
+				a.MusicPlay(&Music{Volume: 1, path: "theme"}, -1)
+				voice := a.Group("voice")
+				voice.Duck = true
+				voice.Play(&Wav{Volume: 1, path: "line"}, nil)
+				tick(10)
+			},
+			vol:   .4,
+			music: []string{"theme"},
+		},
+		{
+			name: "recover when the duck group stops",
+			run: func(a *Audio, s *Stage, tick func(n int)) {
+				a.MusicPlay(&Music{Volume: 1, path: "theme"}, -1)
+				voice := a.Group("voice")
+				voice.Duck = true
+				voice.Play(&Wav{Volume: 1, path: "line"}, nil)
+				tick(10)
+				voice.Stop()
+				tick(10)
+			},
+			vol:   1,
+			music: []string{"theme"},
+		},
+		{
+			name: "fade in",
+			run: func(a *Audio, s *Stage, tick func(n int)) {
+				s.Root.MusicFade(&Music{Volume: .5, path: "theme"}, -1, time.Second)
+				tick(31)
+			},
+			vol:   .5,
+			music: []string{"theme"},
+		},
+		{
+			name: "crossfade",
+			run: func(a *Audio, s *Stage, tick func(n int)) {
+				a.MusicPlay(&Music{Volume: 1, path: "theme"}, -1)
+				s.Root.MusicFade(&Music{Volume: 1, path: "boss"}, -1, time.Second)
+				tick(31)
+			},
+			vol:   1,
+			music: []string{"theme", "boss"},
+		},
+		{
+			name: "fade out",
+			run: func(a *Audio, s *Stage, tick func(n int)) {
+				a.MusicPlay(&Music{Volume: 1, path: "theme"}, -1)
+				s.Root.MusicFade(nil, 0, time.Second)
+				tick(31)
+			},
+			vol:   0,
+			music: []string{"theme"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := audioTest(4)
+			s := stageTest()
+			s.view.Audio = a
+			n := int32(0)
+			tick := func(k int) {
+				for i := 0; i < k; i++ {
+					n++
+					a.Tick(n, dpt)
+					s.Tick(n)
+				}
+			}
+			tick(1)
+			tt.run(a, s, tick)
+			if math.Abs(float64(a.musicVol-tt.vol)) > 1e-4 {
+				t.Errorf("music volume %v, want %v", a.musicVol, tt.vol)
+			}
+			var music []string
+			for _, e := range b.Events {
+				if e.Music {
+					music = append(music, e.Sound)
+				}
+			}
+			if strings.Join(music, " ") != strings.Join(tt.music, " ") {
+				t.Errorf("music %v, want %v", music, tt.music)
+			}
+		})
+	}
+}
+
+// staleBackend finishes the sound on channel 0 on the audio thread just before the next Play
+type staleBackend struct {
+	*RecordBackend
+	hold  *NullBackend
+	stale bool
+}
+
+func (b *staleBackend) Play(w *Wav, ch int, vol, pan float32) (int, error) {
+	if b.stale {
+		b.stale = false
+		b.hold.busy[0] = false
+		b.hold.finished(0)
+	}
+	return b.RecordBackend.Play(w, ch, vol, pan)
+}
+
+func TestAudioStaleFinish(t *testing.T) {
+	hold := &NullBackend{busy: make([]bool, 1)}
+	b := &staleBackend{RecordBackend: MakeRecordBackend(holdBackend{hold}), hold: hold}
+	a := MakeAudio(b)
+	a.Tick(1, 0)
+	croaks, hops := 0, 0
+	a.Group("sfx").Play(&Wav{Volume: 1, path: "croak"}, func() { croaks++ })
+
+	// croak ends between the settling in play and the backend reusing its channel for hop
+	b.stale = true
+	a.Group("sfx").Play(&Wav{Volume: 1, path: "hop"}, func() { hops++ })
+	a.Tick(2, 0)
+	if croaks != 1 || hops != 0 {
+		t.Fatalf("onDone ran for croak %d and hop %d times, want 1 and 0", croaks, hops)
+	}
+	if a.Playing() != 1 || a.channels[0].wav.path != "hop" {
+		t.Errorf("hop is not playing on channel 0")
+	}
+	a.Stop(0)
+	if hops != 1 {
+		t.Errorf("onDone ran for hop %d times after Stop, want 1", hops)
+	}
+	eventsCheck(t, b.RecordBackend, []AudioEvent{{Sound: "croak", Tick: 1, Volume: 1}, {Sound: "hop", Tick: 1, Volume: 1}})
+}
+
+func TestAudioChain(t *testing.T) {
+	hop := &Wav{Volume: 1, path: "hop"}
+	dpt := int64(time.Second / 30)
+	tests := []struct {
+		name string
+		cue  func(d *Dob)
+		want []AudioEvent
+	}{
+		{
+			name: "PlaySound after MoveTo",
+			cue:  func(d *Dob) { d.MoveTo(700, 300, time.Second, nil).PlaySound(hop) },
+			// one second is 30 ticks after the MoveTo starts on tick 1. pan is .8 * (2*700/800 - 1)
+			want: []AudioEvent{{Sound: "hop", Tick: 31, Volume: 1, Pan: .6}},
+		},
+		{
+			name: "Play after MoveTo",
+			cue:  func(d *Dob) { d.MoveTo(200, 300, time.Second/2, nil).Play(hop) },
+			want: []AudioEvent{{Sound: "hop", Tick: 16, Volume: 1, Pan: -.4}},
+		},
+		{
+			name: "PlaySound at once",
+			cue:  func(d *Dob) { d.PlaySound(hop) },
+			want: []AudioEvent{{Sound: "hop", Tick: 1, Volume: 1}},
+		},
+		{
+			name: "PlaySound between moves",
+			cue: func(d *Dob) {
+				d.MoveTo(0, 300, time.Second/3, nil).PlaySound(hop).MoveTo(800, 300, time.Second/3, nil).PlaySound(hop)
+			},
+			want: []AudioEvent{{Sound: "hop", Tick: 11, Volume: 1, Pan: -.8}, {Sound: "hop", Tick: 21, Volume: 1, Pan: .8}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := audioTest(4)
+			s := stageTest()
+			s.view.Audio = a
+			d := s.Root.SpawnRect()
+			d.Move(400, 300)
+			tt.cue(d)
+			for tick := int32(1); tick <= 40; tick++ {
+				a.Tick(tick, dpt)
+				s.Tick(tick)
+			}
+			eventsCheck(t, b, tt.want)
+		})
+	}
+}
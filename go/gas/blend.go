@@ -0,0 +1,43 @@
+package gas
+
+import "github.com/veandco/go-sdl2/sdl"
+
+// Blend is how a dob combines with what paints beneath it
+type Blend int
+
+const (
+	BlendAlpha    Blend = iota // the default. translucent pixels show what is beneath
+	BlendAdd                   // brightens what is beneath. for glows, fire and lights
+	BlendMultiply              // darkens what is beneath by the dob color, respecting its alpha. for shadows and tints
+	BlendMod                   // multiplies what is beneath by the dob color, ignoring its alpha
+	BlendNone                  // overwrites what is beneath, alpha and all
+)
+
+// sdlBlendMul is SDL_BLENDMODE_MUL, which sdl added in 2.0.12 and go-sdl2 does not name yet
+const sdlBlendMul = sdl.BlendMode(0x8)
+
+// sdl returns the sdl blend mode for b
+func (b Blend) sdl() sdl.BlendMode {
+	switch b {
+	case BlendAdd:
+		return sdl.BLENDMODE_ADD
+	case BlendMultiply:
+		return sdlBlendMul
+	case BlendMod:
+		return sdl.BLENDMODE_MOD
+	case BlendNone:
+		return sdl.BLENDMODE_NONE
+	}
+	return sdl.BLENDMODE_BLEND
+}
+
+// blendSet sets the blend mode of the texture for the next copy.
+// Textures are shared by dobs, so this skips the cgo call if the mode is already set.
+// New textures blend alpha, as sdl makes them from images with alpha.
+func (t *Texture) blendSet(b Blend) {
+	if t.blend == b {
+		return
+	}
+	t.SDLTexture.SetBlendMode(b.sdl())
+	t.blend = b
+}
@@ -0,0 +1,241 @@
+package gas
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Cache renders a dob and its subtree into an offscreen texture, then paints the
+// texture as one quad until something in the subtree changes. Use it for static
+// subtrees (eg. terrain), for fading a whole group with Alpha and for thumbnails.
+//
+// The texture covers the rect of the dob at stage resolution, so size the dob
+// (eg. a transparent color rect) to hold its subtree. Anything outside is clipped,
+// but for the dob itself, which may zoom and spin beyond its rect.
+// Cameras move, zoom and spin the quad like any other dob.
+//
+// The cache renders again when a dob in the subtree moves, resizes, spins, zooms, changes
+// texture, color, blend, flip, Z or visibility, or when dobs join or leave. Painters (eg. particles)
+// change without notice, so Invalidate after changing them.
+type Cache struct {
+	Alpha    uint8      // opacity of the whole group. CacheOn sets 0xff
+	dst      sdl.Rect   // where the quad painted as of the last Paint
+	painting bool       // rendering the subtree into the texture
+	self     cacheState // the cached dob as of the last check
+	stale    bool       // render again on the next Paint
+	texture  Texture
+}
+
+// cacheState is everything that decides how a dob paints into a cache
+type cacheState struct {
+	paintState
+	blend  Blend
+	fillC  sdl.Color
+	flip   sdl.RendererFlip
+	hidden bool
+	i      int // index among siblings
+	n      int // number of children
+	z      int
+}
+
+// CacheOn starts caching the subtree of d. See Cache.
+func (d *Dob) CacheOn() *Cache {
+	if d.Cache == nil {
+		d.Cache = &Cache{Alpha: 0xff, stale: true}
+	}
+	return d.Cache
+}
+
+// CacheOff stops caching the subtree of d and releases the texture
+func (d *Dob) CacheOff() {
+	if d.Cache == nil {
+		return
+	}
+	d.Cache.destroy()
+	d.Cache = nil
+}
+
+// Invalidate renders the cache again on the next Paint
+func (c *Cache) Invalidate() {
+	c.stale = true
+}
+
+// Texture returns the rendered subtree, eg. to show a thumbnail with another dob.
+// It holds the last Paint and updates in place.
+func (c *Cache) Texture() *Texture {
+	return &c.texture
+}
+
+// destroy releases the texture
+func (c *Cache) destroy() {
+	if c.texture.SDLTexture != nil {
+		c.texture.SDLTexture.Destroy()
+		c.texture.SDLTexture = nil
+	}
+	c.texture.W, c.texture.H = 0, 0
+}
+
+// cacheBounds returns the stage point at the center of the cache texture and its size:
+// the rect of d, grown to hold the zoomed and rotated quad of d itself
+func (d *Dob) cacheBounds() (x, y float32, w, h int32) {
+	rw, rh := float64(d.D[0])*float64(d.Scale), float64(d.D[1])*float64(d.Scale)
+	if rw <= 0 || rh <= 0 {
+		return d.Px, d.Py, 0, 0
+	}
+	l, t, r, b := -rw/2, -rh/2, rw/2, rh/2
+	if d.zoom != 1 || d.angle != 0 {
+		px, py := d.center()
+		cx, cy := float64(px-d.Px), float64(py-d.Py)
+		qw, qh := float64(d.zoom)*rw, float64(d.zoom)*rh
+		sin, cos := math.Sincos(d.angle * math.Pi / 180)
+		ex := (math.Abs(qw*cos) + math.Abs(qh*sin)) / 2
+		ey := (math.Abs(qw*sin) + math.Abs(qh*cos)) / 2
+		l, t = math.Min(l, cx-ex), math.Min(t, cy-ey)
+		r, b = math.Max(r, cx+ex), math.Max(b, cy+ey)
+	}
+	return d.Px + float32(l+r)/2, d.Py + float32(t+b)/2, int32(math.Ceil(r - l)), int32(math.Ceil(b - t))
+}
+
+// cacheXf returns the transform that maps the stage point x, y to the center of a w x h cache texture
+func cacheXf(x, y float32, w, h int32) xf {
+	return xf{camX: x, camY: y, cx: float32(w) / 2, cy: float32(h) / 2, cos: 1, zoom: 1}
+}
+
+// cachePaint renders the subtree into the cache if stale, then paints the cache
+func (d *Dob) cachePaint() {
+	c := d.Cache
+	stats := &d.Stage.stats
+	cx, cy, w, h := d.cacheBounds()
+	if w <= 0 || h <= 0 {
+		stats.Culled++
+		return
+	}
+	cxf := cacheXf(cx, cy, w, h)
+	if d.cacheStale(&cxf) || c.texture.W != w || c.texture.H != h {
+		if !d.cacheRender(w, h, &cxf) {
+			// no render targets. paint the subtree as usual
+			c.painting = true
+			d.Paint()
+			c.painting = false
+			return
+		}
+	}
+
+	xf := &d.Stage.xf
+	x, y := xf.apply(cx, cy)
+	qw, qh := xf.zoom*float32(w), xf.zoom*float32(h)
+	c.dst = sdl.Rect{X: int32(x - qw/2), Y: int32(y - qh/2), W: int32(qw), H: int32(qh)}
+	t := c.texture.SDLTexture
+	t.SetAlphaMod(c.Alpha)
+	d.Stage.view.Renderer.CopyEx(t, nil, &c.dst, xf.rot, nil, sdl.FLIP_NONE)
+	stats.Painted++
+}
+
+// cacheRender paints the subtree into the cache texture through cxf.
+// Returns false if the renderer has no render targets.
+func (d *Dob) cacheRender(w, h int32, cxf *xf) bool {
+	c := d.Cache
+	s := d.Stage
+	t, err := renderTarget(s.view.Renderer, c.texture.SDLTexture, w, h)
+	if err != nil {
+		c.texture = Texture{}
+		return false
+	}
+	c.texture = Texture{SDLTexture: t, H: h, W: w}
+
+	prev, err := s.renderTo(t)
+	if err != nil {
+		s.renderBack(prev)
+		return false
+	}
+	s.view.Renderer.SetDrawColor(0, 0, 0, 0)
+	s.view.Renderer.Clear()
+	xf := s.xf
+	s.xf = *cxf
+	c.painting = true
+	d.Paint()
+	c.painting = false
+	s.xf = xf
+	c.stale = false
+	s.renderBack(prev)
+	return true
+}
+
+// cacheStale checks the subtree of d for changes since the last check.
+// Returns true if the cache must render again.
+func (d *Dob) cacheStale(cxf *xf) bool {
+	c := d.Cache
+	self := d.cacheStateOf(cxf, 0)
+	if self != c.self {
+		c.self = self
+		c.stale = true
+	}
+	if d.cacheDobsCheck(cxf) {
+		c.stale = true
+	}
+	return c.stale
+}
+
+// cacheStateOf returns the cache state of d through x as child i
+func (d *Dob) cacheStateOf(x *xf, i int) cacheState {
+	n := 0
+	if d.dobs != nil {
+		n = d.dobs.Len()
+	}
+	return cacheState{
+		paintState: paintState{
+			angle:   d.angle,
+			d:       d.D,
+			pivot:   d.Pivot,
+			px:      d.Px,
+			py:      d.Py,
+			scale:   d.Scale,
+			texture: d.Texture,
+			xf:      *x,
+			zoom:    d.zoom,
+		},
+		blend:  d.Blend,
+		fillC:  d.FillC,
+		flip:   d.Flip,
+		i:      i,
+		n:      n,
+		hidden: d.Hidden,
+		z:      d.Z,
+	}
+}
+
+// cacheDobsCheck compares the visible subtree of d to the last check.
+// Checks all of it, so every dob holds its current state. Nested caches check their own subtrees.
+func (d *Dob) cacheDobsCheck(x *xf) bool {
+	if d.dobs == nil {
+		return false
+	}
+	changed := false
+	i := 0
+	d.dobs.Range(func(id int64, b *Dob) bool {
+		// we need this check to support racing Ans
+		if b == nil {
+			return true
+		}
+		s := b.cacheStateOf(x, i)
+		i++
+		if s != b.cacheLast {
+			b.cacheLast = s
+			changed = true
+		}
+		if b.Hidden {
+			return true
+		}
+		if b.Cache != nil {
+			bxf := cacheXf(b.cacheBounds())
+			if b.cacheStale(&bxf) {
+				changed = true
+			}
+		} else if b.cacheDobsCheck(x) {
+			changed = true
+		}
+		return true
+	})
+	return changed
+}
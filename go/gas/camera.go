@@ -0,0 +1,169 @@
+package gas
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// xf maps stage coordinates to the current render target
+type xf struct {
+	camX float32 // stage point that maps to (cx, cy)
+	camY float32
+	cos  float32
+	cx   float32 // center of the render target
+	cy   float32
+	rot  float64 // rotation in degrees
+	sin  float32
+	zoom float32
+}
+
+// xfIdentity returns an xf that leaves coordinates as they are on a w x h target
+func xfIdentity(w, h int32) xf {
+	return xf{camX: float32(w) / 2, camY: float32(h) / 2, cx: float32(w) / 2, cy: float32(h) / 2, cos: 1, zoom: 1}
+}
+
+// apply maps a stage point to the render target
+func (x *xf) apply(px, py float32) (float32, float32) {
+	dx := (px - x.camX) * x.zoom
+	dy := (py - x.camY) * x.zoom
+	return x.cx + dx*x.cos - dy*x.sin, x.cy + dx*x.sin + dy*x.cos
+}
+
+// Camera views a subtree of the stage through a viewport of the View.
+// Move, Zoom and Spin the camera with the usual Ans to pan, zoom and rotate
+// the view without touching the game objects. Add several for split screen.
+type Camera struct {
+	Dob
+	Bounds     *sdl.FRect // keep the view inside these stage bounds. nil for none
+	Target     *Dob       // root of the subtree to render. nil renders Root and all layers
+	Viewport   sdl.Rect   // region of the View to render into
+	clip       sdl.Rect
+	follow     *Dob
+	followRate float32
+	shakeX     float32
+	shakeY     float32
+}
+
+// CameraAdd adds a Camera rendering target (or the whole stage if nil) to the full view.
+// Once a stage has cameras, it renders only through them.
+func (s *Stage) CameraAdd(target *Dob) *Camera {
+	dobID++
+	c := &Camera{Target: target, Viewport: sdl.Rect{X: 0, Y: 0, W: s.view.W, H: s.view.H}}
+	c.id = dobID
+	c.dob = &c.Dob
+	c.Px = float32(s.view.W) / 2
+	c.Py = float32(s.view.H) / 2
+	c.Scale = 1
+	c.Stage = s
+	c.zoom = 1
+	s.Cameras = append(s.Cameras, c)
+	return c
+}
+
+// CameraRm removes c from the stage
+func (s *Stage) CameraRm(c *Camera) {
+	for i, b := range s.Cameras {
+		if b == c {
+			s.Cameras = append(s.Cameras[:i], s.Cameras[i+1:]...)
+			return
+		}
+	}
+}
+
+// Follow keeps the camera centered on dob. Each tick the camera closes rate
+// (0 to 1) of the distance to the dob; 1 snaps. Follow(nil, 0) stops following.
+func (c *Camera) Follow(dob *Dob, rate float32) {
+	c.follow = dob
+	c.followRate = rate
+}
+
+// update follows and clamps after the camera Ans tick
+func (c *Camera) update() {
+	if c.follow != nil {
+		c.Px += (c.follow.Px - c.Px) * c.followRate
+		c.Py += (c.follow.Py - c.Py) * c.followRate
+	}
+	if c.Bounds != nil {
+		hw := float32(c.Viewport.W) / 2 / c.zoom
+		hh := float32(c.Viewport.H) / 2 / c.zoom
+		c.Px = clamp(c.Px, c.Bounds.X+hw, c.Bounds.X+c.Bounds.W-hw)
+		c.Py = clamp(c.Py, c.Bounds.Y+hh, c.Bounds.Y+c.Bounds.H-hh)
+	}
+}
+
+// clamp limits v to [lo, hi]. If the range is empty, returns the midpoint.
+func clamp(v, lo, hi float32) float32 {
+	if lo > hi {
+		return (lo + hi) / 2
+	}
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// xf returns the transform for rendering through the camera
+func (c *Camera) xf() xf {
+	sin, cos := math.Sincos(-c.angle * math.Pi / 180)
+	return xf{
+		camX: c.Px + c.shakeX,
+		camY: c.Py + c.shakeY,
+		cos:  float32(cos),
+		cx:   float32(c.Viewport.W) / 2,
+		cy:   float32(c.Viewport.H) / 2,
+		rot:  -c.angle,
+		sin:  float32(sin),
+		zoom: c.zoom,
+	}
+}
+
+// paint renders the target subtree into the viewport
+func (c *Camera) paint() {
+	r := c.Stage.view.Renderer
+	r.SetViewport(&c.Viewport)
+	c.clip = sdl.Rect{X: 0, Y: 0, W: c.Viewport.W, H: c.Viewport.H}
+	r.SetClipRect(&c.clip)
+	if c.Target == nil {
+		c.Stage.layersPaint(c.xf())
+	} else {
+		c.Stage.xf = c.xf()
+		c.Target.Paint()
+	}
+	r.SetClipRect(nil)
+	r.SetViewport(nil)
+}
+
+// ShakeAn jiggles a camera by up to mag px, decaying to rest over the duration
+type ShakeAn struct {
+	BaseAn
+	cam *Camera
+	mag float32
+}
+
+// Shake yields a ShakeAn for the camera
+func (c *Camera) Shake(mag float32, duration time.Duration) *ShakeAn {
+	anID++
+	b := &ShakeAn{BaseAn: BaseAn{id: anID, dob: &c.Dob, anSet: nil, Duration: int64(duration), Easer: EaseNone}, cam: c, mag: mag}
+	return c.AnSetAdd(b).(*ShakeAn)
+}
+
+func (a *ShakeAn) Tick(tick int32) bool {
+	if a.StartTick == 0 {
+		a.StartTick = tick
+	}
+	pct, eased := a.PC(tick)
+	if pct == 1 {
+		a.cam.shakeX, a.cam.shakeY = 0, 0
+		return true
+	}
+	m := a.mag * (1 - eased)
+	a.cam.shakeX = m * (2*rand.Float32() - 1)
+	a.cam.shakeY = m * (2*rand.Float32() - 1)
+	return false
+}
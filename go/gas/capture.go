@@ -0,0 +1,268 @@
+package gas
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Screenshot saves the next frame of the stage to path as a PNG, without the debug overlay.
+// The stage captures the frame as it paints and writes the file in the background,
+// logging the outcome to Log.
+func (s *Stage) Screenshot(path string) {
+	s.shots = append(s.shots, path)
+}
+
+// shotsSave captures the render target for the queued screenshots. Paint calls it before the overlay.
+func (s *Stage) shotsSave() {
+	rgba := s.view.capture(nil)
+	for i, path := range s.shots {
+		s.shots[i] = ""
+		go func(path string) {
+			if err := pngSave(path, rgba); err != nil {
+				s.Log.Error("screenshot", "path", path, "err", err)
+				return
+			}
+			s.Log.Info("screenshot saved", "path", path)
+		}(path)
+	}
+	s.shots = s.shots[:0]
+}
+
+// capture reads the current render target into an image, reusing rgba if it fits
+func (v *View) capture(rgba *image.RGBA) *image.RGBA {
+	r := v.Renderer
+	vp := r.GetViewport()
+	w, h := vp.W, vp.H
+	if r.GetRenderTarget() == nil {
+		// the default target reads in output pixels, which differ on high-DPI displays
+		if ow, oh, err := r.GetOutputSize(); err == nil && !v.logical {
+			w, h = ow, oh
+		}
+	}
+	if rgba == nil || rgba.Rect.Dx() != int(w) || rgba.Rect.Dy() != int(h) {
+		rgba = image.NewRGBA(image.Rect(0, 0, int(w), int(h)))
+	}
+	r.ReadPixels(nil, uint32(sdl.PIXELFORMAT_ABGR8888), unsafe.Pointer(&rgba.Pix[0]), rgba.Stride)
+	if r.GetRenderTarget() == nil {
+		// window alpha means nothing
+		for i := 3; i < len(rgba.Pix); i += 4 {
+			rgba.Pix[i] = 0xff
+		}
+	}
+	return rgba
+}
+
+// pngSave writes img to path, making the directory if needed
+func pngSave(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Recorder captures every frame a Director renders, at the tick rate whatever the frame rate.
+// To a GIF if the path ends in .gif, else to a PNG sequence (see Director.Record).
+// GIFs keep every frame in memory until RecordStop, so keep recordings short or set Every.
+type Recorder struct {
+	Every  int     // keep every nth frame. defaults to 1
+	Max    int     // stop the director after this many kept frames. 0 for no limit
+	delay  float64 // time owed to the next gif frame, in 100ths of a second
+	err    error   // the first error writing frames
+	frames []*image.Paletted
+	gif    bool
+	kept   int // frames kept since the start
+	n      int // frames rendered since the start
+	path   string
+	quant  *quantizer
+	rgba   *image.RGBA
+	times  []int // gif frame delays in 100ths of a second
+}
+
+// Record starts recording each frame the director renders to path, making its directory.
+// A path ending in .gif records an animated GIF. Anything else is a pattern for
+// a PNG sequence with one integer verb for the frame number, eg. "shots/frame-%05d.png".
+// A path without a verb gets "-%05d" before its extension.
+// Recording works headless (eg. SDL_VIDEODRIVER=dummy). Call RecordStop to finish.
+func (d *Director) Record(path string) (*Recorder, error) {
+	gif := strings.HasSuffix(strings.ToLower(path), ".gif")
+	if !gif {
+		verbs, err := frameVerbs(path)
+		if err != nil {
+			return nil, err
+		}
+		if verbs == 0 {
+			ext := filepath.Ext(path)
+			path = strings.TrimSuffix(path, ext) + "-%05d" + ext
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	d.Recorder = &Recorder{Every: 1, gif: gif, path: path}
+	return d.Recorder, nil
+}
+
+// frameVerbs counts the integer verbs in a PNG sequence pattern. Errors on any other verb or on more than one.
+func frameVerbs(path string) (n int, err error) {
+	for i := 0; i < len(path); i++ {
+		if path[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(path) && path[i] == '%' {
+			continue
+		}
+		// flags and width, eg. %05d
+		for i < len(path) && strings.IndexByte("+-# 0123456789", path[i]) >= 0 {
+			i++
+		}
+		if i == len(path) || path[i] != 'd' {
+			return 0, fmt.Errorf("record path %q: the frame number takes one integer verb, eg. %%05d. write %%%% for a %%", path)
+		}
+		n++
+	}
+	if n > 1 {
+		return 0, fmt.Errorf("record path %q: the frame number takes one integer verb, not %d", path, n)
+	}
+	return n, nil
+}
+
+// capture keeps the frame the director just rendered
+func (rec *Recorder) capture(d *Director) {
+	rec.n++
+	if rec.Every > 1 && (rec.n-1)%rec.Every != 0 {
+		return
+	}
+	rec.rgba = d.view.capture(rec.rgba)
+	rec.kept++
+	if !rec.gif {
+		if err := pngSave(fmt.Sprintf(rec.path, rec.kept), rec.rgba); err != nil && rec.err == nil {
+			rec.err = err
+		}
+	} else {
+		if rec.quant == nil {
+			rec.quant = &quantizer{}
+		}
+		rec.frames = append(rec.frames, rec.quant.quantize(rec.rgba))
+		// gif delays are in 100ths of a second. carry the rounding so the total time stays true
+		every := rec.Every
+		if every < 1 {
+			every = 1
+		}
+		rec.delay += float64(d.DurationPerTick*int64(every)) / 1e7
+		delay := int(rec.delay + .5)
+		rec.delay -= float64(delay)
+		rec.times = append(rec.times, delay)
+	}
+	if rec.Max > 0 && rec.kept >= rec.Max {
+		d.Stop()
+	}
+}
+
+// Frames returns the number of frames kept so far
+func (rec *Recorder) Frames() int {
+	return rec.kept
+}
+
+// RecordStop stops recording and writes the GIF, if recording one.
+// Returns the first error writing frames.
+func (d *Director) RecordStop() error {
+	rec := d.Recorder
+	if rec == nil {
+		return nil
+	}
+	d.Recorder = nil
+	if rec.err != nil || !rec.gif || len(rec.frames) == 0 {
+		return rec.err
+	}
+	f, err := os.Create(rec.path)
+	if err != nil {
+		return err
+	}
+	err = gif.EncodeAll(f, &gif.GIF{Image: rec.frames, Delay: rec.times})
+	rec.frames, rec.times = nil, nil
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// quantizer picks palettes for gif frames. Buckets colors at 5 bits per channel.
+type quantizer struct {
+	hist [1 << 15]int32    // pixels per bucket
+	keys []int             // buckets in use, fullest first
+	lut  [1 << 15]uint8    // palette index per bucket
+	sum  [1 << 15][3]int64 // summed color per bucket
+}
+
+// quantize maps img onto a palette of the average colors of its 256 fullest buckets.
+// Colors in other buckets map to the nearest in the palette. Images with few colors map exactly.
+func (q *quantizer) quantize(img *image.RGBA) *image.Paletted {
+	q.hist = [1 << 15]int32{}
+	q.sum = [1 << 15][3]int64{}
+	pix := img.Pix
+	for i := 0; i+3 < len(pix); i += 4 {
+		k := int(pix[i]>>3)<<10 | int(pix[i+1]>>3)<<5 | int(pix[i+2]>>3)
+		q.hist[k]++
+		q.sum[k][0] += int64(pix[i])
+		q.sum[k][1] += int64(pix[i+1])
+		q.sum[k][2] += int64(pix[i+2])
+	}
+	q.keys = q.keys[:0]
+	for k, n := range q.hist {
+		if n > 0 {
+			q.keys = append(q.keys, k)
+		}
+	}
+	sort.Slice(q.keys, func(i, j int) bool { return q.hist[q.keys[i]] > q.hist[q.keys[j]] })
+	n := len(q.keys)
+	if n > 256 {
+		n = 256
+	}
+	pal := make(color.Palette, n)
+	for i, k := range q.keys {
+		if i < n {
+			c := int64(q.hist[k])
+			pal[i] = color.RGBA{R: uint8(q.sum[k][0] / c), G: uint8(q.sum[k][1] / c), B: uint8(q.sum[k][2] / c), A: 0xff}
+			q.lut[k] = uint8(i)
+			continue
+		}
+		// the nearest palette color to the average of the bucket
+		c := int64(q.hist[k])
+		r, g, b := q.sum[k][0]/c, q.sum[k][1]/c, q.sum[k][2]/c
+		best, bestD := 0, int64(1<<62)
+		for j, pc := range pal {
+			p := pc.(color.RGBA)
+			dr, dg, db := r-int64(p.R), g-int64(p.G), b-int64(p.B)
+			if d := dr*dr + dg*dg + db*db; d < bestD {
+				best, bestD = j, d
+			}
+		}
+		q.lut[k] = uint8(best)
+	}
+
+	p := image.NewPaletted(img.Rect, pal)
+	for i, j := 0, 0; i+3 < len(pix); i, j = i+4, j+1 {
+		p.Pix[j] = q.lut[int(pix[i]>>3)<<10|int(pix[i+1]>>3)<<5|int(pix[i+2]>>3)]
+	}
+	return p
+}
@@ -0,0 +1,134 @@
+package gas
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFrameVerbs(t *testing.T) {
+	tests := []struct {
+		path string
+		n    int
+		err  bool
+	}{
+		{"shots/frame.png", 0, false},
+		{"shots/frame-%d.png", 1, false},
+		{"shots/frame-%05d.png", 1, false},
+		{"shots/%-4d.png", 1, false},
+		{"shots/100%%-%03d.png", 1, false},
+		{"shots/100%%.png", 0, false},
+		{"shots/%s.png", 0, true},
+		{"shots/%05x.png", 0, true},
+		{"shots/%d-%d.png", 0, true},
+		{"shots/frame%", 0, true},
+		{"shots/frame%05", 0, true},
+	}
+	for _, tt := range tests {
+		n, err := frameVerbs(tt.path)
+		if n != tt.n || (err != nil) != tt.err {
+			t.Errorf("frameVerbs(%q) = %d, %v, want %d and error %v", tt.path, n, err, tt.n, tt.err)
+		}
+	}
+}
+
+func TestRecordPath(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		path, want string
+		err        bool
+	}{
+		{"png/frame.png", "png/frame-%05d.png", false},
+		{"verb/frame-%03d.png", "verb/frame-%03d.png", false},
+		{"gif/play.GIF", "gif/play.GIF", false},
+		{"bad/frame-%s.png", "", true},
+	}
+	for _, tt := range tests {
+		d := &Director{}
+		rec, err := d.Record(filepath.Join(dir, tt.path))
+		if (err != nil) != tt.err {
+			t.Errorf("Record(%q) error %v, want error %v", tt.path, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if rec.path != filepath.Join(dir, tt.want) {
+			t.Errorf("Record(%q) writes %q, want %q", tt.path, rec.path, filepath.Join(dir, tt.want))
+		}
+		if _, err := os.Stat(filepath.Dir(rec.path)); err != nil {
+			t.Errorf("Record(%q) did not make its directory: %v", tt.path, err)
+		}
+	}
+}
+
+// rgbaOf returns a w wide image of the colors in cs, row by row
+func rgbaOf(w int, cs []color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, (len(cs)+w-1)/w))
+	for i, c := range cs {
+		img.SetRGBA(i%w, i/w, c)
+	}
+	return img
+}
+
+func TestQuantizeExact(t *testing.T) {
+	red, green, blue := color.RGBA{R: 0xff, A: 0xff}, color.RGBA{G: 0x80, A: 0xff}, color.RGBA{R: 1, G: 2, B: 3, A: 0xff}
+	img := rgbaOf(4, []color.RGBA{
+		red, red, green, blue,
+		red, green, red, blue,
+	})
+	q := &quantizer{}
+	p := q.quantize(img)
+	if len(p.Palette) != 3 || p.Palette[0] != red {
+		t.Errorf("palette %v, want 3 colors with red first", p.Palette)
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if got, want := p.At(x, y), img.At(x, y); got != want {
+				t.Errorf("pixel %d, %d is %v, want %v", x, y, got, want)
+			}
+		}
+	}
+
+	// the quantizer starts afresh for each frame
+	p = q.quantize(rgbaOf(2, []color.RGBA{blue, blue}))
+	if len(p.Palette) != 1 || p.Palette[0] != blue {
+		t.Errorf("second frame palette %v, want only blue", p.Palette)
+	}
+}
+
+func TestQuantizeNearest(t *testing.T) {
+	// 256 common colors fill the palette. rare colors a bucket of blue away from some map to them
+	common := func(i int) color.RGBA {
+		return color.RGBA{R: uint8(i%16) * 16, G: uint8(i/16) * 16, B: 128, A: 0xff}
+	}
+	var cs []color.RGBA
+	for i := 0; i < 256; i++ {
+		c := common(i)
+		cs = append(cs, c, c, c, c)
+	}
+	rare := []int{0, 37, 255}
+	for _, i := range rare {
+		c := common(i)
+		c.B = 140
+		cs = append(cs, c)
+	}
+	img := rgbaOf(1, cs) // a column, so no padding pixels join the count
+	p := (&quantizer{}).quantize(img)
+	if len(p.Palette) != 256 {
+		t.Fatalf("%d palette colors, want 256", len(p.Palette))
+	}
+	for i := 0; i < 4*256; i++ {
+		if got := p.At(0, i); got != cs[i] {
+			t.Fatalf("common pixel %d is %v, want %v", i, got, cs[i])
+		}
+	}
+	for j, i := range rare {
+		k := 4*256 + j
+		if got := p.At(0, k); got != common(i) {
+			t.Errorf("rare color %v maps to %v, want %v", cs[k], got, common(i))
+		}
+	}
+}
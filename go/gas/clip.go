@@ -0,0 +1,211 @@
+package gas
+
+import (
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// clipState is a clip rect of the renderer, saved while a Clip dob paints
+type clipState struct {
+	enabled bool
+	rect    sdl.Rect
+}
+
+// clipPush confines painting to the rect of d, within the clip of its ancestors.
+// Returns false if nothing of d shows, so the subtree can be skipped.
+func (d *Dob) clipPush() bool {
+	s := d.Stage
+	r := s.view.Renderer
+	prev := clipState{enabled: r.IsClipEnabled(), rect: r.GetClipRect()}
+	rect := d.dst
+	if prev.enabled {
+		var ok bool
+		if rect, ok = rect.Intersect(&prev.rect); !ok {
+			return false
+		}
+	}
+	if rect.Empty() {
+		return false
+	}
+	s.clips = append(s.clips, prev)
+	r.SetClipRect(&rect)
+	return true
+}
+
+// clipPop restores the clip rect from before clipPush
+func (d *Dob) clipPop() {
+	s := d.Stage
+	prev := s.clips[len(s.clips)-1]
+	s.clips = s.clips[:len(s.clips)-1]
+	if prev.enabled {
+		s.view.Renderer.SetClipRect(&prev.rect)
+	} else {
+		s.view.Renderer.SetClipRect(nil)
+	}
+}
+
+// renderState is the renderer state to restore after painting into a texture
+type renderState struct {
+	clip     clipState
+	target   *sdl.Texture
+	viewport sdl.Rect
+}
+
+// renderTo points the renderer at texture t and returns the state to restore with renderBack
+func (s *Stage) renderTo(t *sdl.Texture) (prev renderState, err error) {
+	r := s.view.Renderer
+	prev = renderState{
+		clip:     clipState{enabled: r.IsClipEnabled(), rect: r.GetClipRect()},
+		target:   r.GetRenderTarget(),
+		viewport: r.GetViewport(),
+	}
+	return prev, r.SetRenderTarget(t)
+}
+
+// renderBack restores the renderer state from before renderTo
+func (s *Stage) renderBack(prev renderState) {
+	r := s.view.Renderer
+	r.SetRenderTarget(prev.target)
+	if prev.target == nil {
+		// sdl restores the rest for the default target
+		return
+	}
+	r.SetViewport(&prev.viewport)
+	if prev.clip.enabled {
+		r.SetClipRect(&prev.clip.rect)
+	}
+}
+
+// renderTarget returns t if it is a w x h render target, else a new one in its place
+func renderTarget(r *sdl.Renderer, t *sdl.Texture, w, h int32) (*sdl.Texture, error) {
+	if t != nil {
+		if _, _, tw, th, err := t.Query(); err == nil && tw == w && th == h {
+			return t, nil
+		}
+		t.Destroy()
+	}
+	t, err := r.CreateTexture(uint32(sdl.PIXELFORMAT_ARGB8888), sdl.TEXTUREACCESS_TARGET, w, h)
+	if err != nil {
+		return nil, err
+	}
+	t.SetBlendMode(sdl.BLENDMODE_BLEND)
+	return t, nil
+}
+
+// maskBlend keeps the destination color and multiplies its alpha by the source alpha
+var maskBlend = sdl.ComposeCustomBlendMode(
+	sdl.BLENDFACTOR_ZERO, sdl.BLENDFACTOR_ONE, sdl.BLENDOPERATION_ADD,
+	sdl.BLENDFACTOR_ZERO, sdl.BLENDFACTOR_SRC_ALPHA, sdl.BLENDOPERATION_ADD)
+
+// mask renders a masked subtree. Both textures match the viewport.
+type mask struct {
+	alpha    *sdl.Texture // the mask dob
+	content  *sdl.Texture // the subtree
+	cpu      bool         // the renderer has no maskBlend, so multiply on the cpu
+	painting bool         // rendering the subtree into content
+	pixels   [2][]byte    // content and alpha pixels for cpu masking
+}
+
+// MaskSpawn masks d and its subtree with a new dob, so they paint only where the mask
+// is opaque and fade where it is translucent. The mask dob (an image at path, or a color
+// rect for "") is not in the tree. Position and animate it in stage coordinates like any dob.
+// Masks render the subtree offscreen through render targets, multiplying on the cpu
+// if the renderer cannot blend the alpha (eg. the software renderer).
+func (d *Dob) MaskSpawn(path string) (*Dob, error) {
+	m, err := d.Spawn(path)
+	if err != nil {
+		return nil, err
+	}
+	d.DobRm(m)
+	if d.Layout != nil {
+		d.Layout.Layout(d)
+	}
+	d.MaskRm()
+	d.Mask = m
+	return m, nil
+}
+
+// MaskRm unmasks d, clearing the mask dob and releasing the textures
+func (d *Dob) MaskRm() {
+	if d.Mask != nil {
+		d.Mask.Clear()
+		d.Mask.release()
+		d.Mask = nil
+	}
+	if m := d.mask; m != nil {
+		if m.alpha != nil {
+			m.alpha.Destroy()
+		}
+		if m.content != nil {
+			m.content.Destroy()
+		}
+		d.mask = nil
+	}
+}
+
+// maskPaint renders the subtree and the mask offscreen, masks the subtree and copies it
+// to the viewport. Returns false if the renderer has no render targets.
+func (d *Dob) maskPaint() bool {
+	s := d.Stage
+	r := s.view.Renderer
+	if d.mask == nil {
+		d.mask = &mask{}
+	}
+	m := d.mask
+	vp := r.GetViewport()
+	var err error
+	if m.alpha, err = renderTarget(r, m.alpha, vp.W, vp.H); err != nil {
+		return false
+	}
+	if m.content, err = renderTarget(r, m.content, vp.W, vp.H); err != nil {
+		return false
+	}
+	if !m.cpu && m.alpha.SetBlendMode(maskBlend) != nil {
+		m.cpu = true
+	}
+
+	prev, err := s.renderTo(m.alpha)
+	if err != nil {
+		s.renderBack(prev)
+		return false
+	}
+	r.SetDrawColor(0, 0, 0, 0)
+	r.Clear()
+	d.Mask.Paint()
+	if m.cpu {
+		m.pixels[1] = maskRead(r, m.pixels[1], vp.W, vp.H)
+	}
+
+	r.SetRenderTarget(m.content)
+	r.SetDrawColor(0, 0, 0, 0)
+	r.Clear()
+	m.painting = true
+	d.Paint()
+	m.painting = false
+	if m.cpu {
+		m.pixels[0] = maskRead(r, m.pixels[0], vp.W, vp.H)
+		content, alpha := m.pixels[0], m.pixels[1]
+		for i := 3; i < len(content); i += 4 {
+			content[i] = uint8(uint16(content[i]) * uint16(alpha[i]) / 0xff)
+		}
+		m.content.Update(nil, unsafe.Pointer(&content[0]), int(vp.W*4))
+	} else {
+		r.Copy(m.alpha, nil, nil)
+	}
+
+	s.renderBack(prev)
+	r.Copy(m.content, nil, nil)
+	return true
+}
+
+// maskRead reads the w x h render target as ARGB into buf, growing it if needed
+func maskRead(r *sdl.Renderer, buf []byte, w, h int32) []byte {
+	n := int(w * h * 4)
+	if cap(buf) < n {
+		buf = make([]byte, n)
+	}
+	buf = buf[:n]
+	r.ReadPixels(nil, uint32(sdl.PIXELFORMAT_ARGB8888), unsafe.Pointer(&buf[0]), int(w*4))
+	return buf
+}
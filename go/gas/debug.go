@@ -0,0 +1,511 @@
+package gas
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// Debug overlays frame timing, counts and a dob inspector on a stage. see DebugOn
+//
+// The inspector lists the dob tree. Click a row to select its dob, or its arrow to
+// expand or collapse it. PageUp and PageDown scroll. The selected dob shows its
+// properties and running Ans.
+type Debug struct {
+	Boxes    bool // outlines every dob with its bounds, pivot and id
+	Inspect  bool // shows the dob tree and the selected dob
+	Visible  bool
+	ans      int            // ans on the stage, counting chained ans
+	boxes    []debugBox     // dobs painted this frame
+	dobs     int            // dobs on the stage
+	expanded map[int64]bool // tree rows showing their children, by dob id
+	font     *ttf.Font
+	frameI   int           // next slot in frames
+	frameT   time.Time     // start of the last frame
+	frames   [120]float32  // frame times in ms
+	glyphs   [128]sdl.Rect // glyph rects in the atlas, for ASCII
+	lineH    int32
+	rows     []debugRow // tree rows as last drawn, for clicks
+	scroll   int        // first tree row shown
+	selected *Dob
+	stage    *Stage
+	sub      int          // Input subscription
+	texBytes int64        // texture memory
+	texture  *sdl.Texture // glyph atlas
+}
+
+// debugBox is where a dob painted
+type debugBox struct {
+	angle  float64
+	dst    sdl.Rect
+	id     int64
+	pivotX float32
+	pivotY float32
+	vp     sdl.Rect // viewport of the render
+}
+
+// debugRow is a row of the tree
+type debugRow struct {
+	dob   *Dob
+	depth int
+	y     int32
+}
+
+// DebugOn shows the debug overlay on the stage, writing in font.
+// Toggle its Visible, Boxes and Inspect fields, eg. from key bindings.
+func (s *Stage) DebugOn(font *ttf.Font) *Debug {
+	if s.debug == nil {
+		g := &Debug{Inspect: true, expanded: map[int64]bool{s.Root.id: true}, font: font, stage: s}
+		g.sub = s.Input.On(g.input)
+		s.debug = g
+	}
+	s.debug.Visible = true
+	return s.debug
+}
+
+// DebugOff removes the debug overlay
+func (s *Stage) DebugOff() {
+	g := s.debug
+	if g == nil {
+		return
+	}
+	s.Input.Off(g.sub)
+	if g.texture != nil {
+		g.texture.Destroy()
+	}
+	s.debug = nil
+}
+
+// Toggle shows or hides the overlay
+func (g *Debug) Toggle() {
+	g.Visible = !g.Visible
+}
+
+// Select inspects d
+func (g *Debug) Select(d *Dob) {
+	g.selected = d
+	// expand the ancestors so the row shows
+	for c := d.ctx; c != nil; c = c.ctx {
+		g.expanded[c.id] = true
+	}
+}
+
+// input selects, expands and scrolls the tree
+func (g *Debug) input(e InputEvent) {
+	if !g.Visible || !g.Inspect || !e.Down {
+		return
+	}
+	switch e.Button {
+	case Key(sdl.SCANCODE_PAGEUP):
+		g.scroll -= 10
+		if g.scroll < 0 {
+			g.scroll = 0
+		}
+	case Key(sdl.SCANCODE_PAGEDOWN):
+		g.scroll += 10
+	case MouseLeft:
+		if e.X > debugTreeW {
+			return
+		}
+		for _, row := range g.rows {
+			if e.Y < float32(row.y) || e.Y >= float32(row.y+g.lineH) {
+				continue
+			}
+			if e.X < float32(debugPad+row.depth*debugIndent+debugIndent) {
+				g.expanded[row.dob.id] = !g.expanded[row.dob.id]
+			} else {
+				g.selected = row.dob
+			}
+			return
+		}
+	}
+}
+
+// boxAdd records where d painted. Dob.Paint calls it while Boxes shows.
+func (g *Debug) boxAdd(d *Dob) {
+	r := d.Stage.view.Renderer
+	if r.GetRenderTarget() != nil {
+		// painting offscreen, eg. to a cache
+		return
+	}
+	w, h := d.size()
+	xf := &d.Stage.xf
+	px, py := xf.apply(d.Px+d.Pivot[0]*w, d.Py+d.Pivot[1]*h)
+	g.boxes = append(g.boxes, debugBox{angle: d.angle + xf.rot, dst: d.dst, id: d.id, pivotX: px, pivotY: py, vp: r.GetViewport()})
+}
+
+const (
+	debugGraphH = 48  // px for 50ms
+	debugIndent = 12  // px per tree level
+	debugPad    = 8   // px around the panels
+	debugPropsW = 380 // px for the selected dob
+	debugTreeW  = 320 // px for the tree
+)
+
+// paint draws the overlay over the stage. Stage.Paint calls it last.
+func (g *Debug) paint() {
+	now := time.Now()
+	if !g.frameT.IsZero() {
+		g.frames[g.frameI] = float32(now.Sub(g.frameT).Seconds() * 1000)
+		g.frameI = (g.frameI + 1) % len(g.frames)
+	}
+	g.frameT = now
+	boxes := g.boxes
+	g.boxes = g.boxes[:0]
+	if !g.Visible {
+		return
+	}
+	if g.texture == nil && !g.atlas() {
+		return
+	}
+
+	s := g.stage
+	r := s.view.Renderer
+	r.SetViewport(nil)
+	r.SetClipRect(nil)
+	r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	if g.Boxes {
+		g.boxesPaint(boxes)
+	}
+
+	// counts
+	g.dobs, g.ans, g.texBytes = 0, 0, s.view.Assets.textureBytes(r)
+	g.count(s.Root)
+	for _, l := range s.layers {
+		g.count(&l.Dob)
+	}
+	var sum, worst float32
+	for _, ms := range g.frames {
+		sum += ms
+		if ms > worst {
+			worst = ms
+		}
+	}
+	avg := sum / float32(len(g.frames))
+	fps := float32(0)
+	if avg > 0 {
+		fps = 1000 / avg
+	}
+	stats := s.Stats()
+	lines := [...]string{
+		fmt.Sprintf("FPS %.1f  frame %.1fms  worst %.1fms  tick %d", fps, avg, worst, stats.Tick),
+		fmt.Sprintf("dobs %d  ans %d  painted %d  culled %d", g.dobs, g.ans, stats.Painted, stats.Culled),
+		fmt.Sprintf("textures %.1fMB  allocs/frame %d", float64(g.texBytes)/(1<<20), stats.Allocs),
+	}
+	y := int32(debugPad)
+	h := int32(len(lines))*g.lineH + debugGraphH + 3*debugPad
+	g.panel(0, 0, debugTreeW, h)
+	for _, line := range lines {
+		g.text(debugPad, y, line, sdl.Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+		y += g.lineH
+	}
+	g.graph(y+debugPad, float32(s.DurationPerTick)/1e6)
+	y += debugGraphH + 2*debugPad
+
+	if g.Inspect {
+		g.treePaint(y)
+		g.propsPaint()
+	}
+}
+
+// count adds the dobs and ans in the subtree of d
+func (g *Debug) count(d *Dob) {
+	g.dobs++
+	for _, an := range d.anSet {
+		g.ans += anCount(an)
+	}
+	if d.Cache != nil && d.Cache.texture.SDLTexture != nil {
+		g.texBytes += int64(d.Cache.texture.W) * int64(d.Cache.texture.H) * 4
+	}
+	if d.txt != "" && d.Texture != nil {
+		g.texBytes += int64(d.Texture.W) * int64(d.Texture.H) * 4
+	}
+	d.dobs.Range(func(id int64, b *Dob) bool {
+		if b != nil {
+			g.count(b)
+		}
+		return true
+	})
+}
+
+// anCount counts an and the ans chained to it
+func anCount(an An) int {
+	n := 1
+	for _, next := range an.AnSet() {
+		n += anCount(next)
+	}
+	return n
+}
+
+// graph draws frame times as bars, with a line at the target frame time
+func (g *Debug) graph(y int32, targetMs float32) {
+	r := g.stage.view.Renderer
+	for i := range g.frames {
+		ms := g.frames[(g.frameI+i)%len(g.frames)]
+		h := int32(ms / 50 * debugGraphH)
+		if h > debugGraphH {
+			h = debugGraphH
+		}
+		if targetMs > 0 && ms > targetMs*1.5 {
+			r.SetDrawColor(0xff, 0x50, 0x50, 0xff)
+		} else {
+			r.SetDrawColor(0x50, 0xff, 0x50, 0xff)
+		}
+		r.FillRect(&sdl.Rect{X: debugPad + int32(i)*2, Y: y + debugGraphH - h, W: 2, H: h})
+	}
+	if targetMs > 0 {
+		ty := y + debugGraphH - int32(targetMs/50*debugGraphH)
+		r.SetDrawColor(0xff, 0xff, 0xff, 0x80)
+		r.DrawLine(debugPad, ty, debugPad+int32(len(g.frames))*2, ty)
+	}
+}
+
+// treePaint draws the rows of the dob tree from y down
+func (g *Debug) treePaint(y int32) {
+	s := g.stage
+	g.rows = g.rows[:0]
+	g.treeRows(s.Root, 0)
+	for _, l := range s.layers {
+		g.treeRows(&l.Dob, 0)
+	}
+	shown := int(int32(s.view.H)-y-debugPad) / int(g.lineH)
+	if g.scroll > len(g.rows)-shown {
+		g.scroll = len(g.rows) - shown
+	}
+	if g.scroll < 0 {
+		g.scroll = 0
+	}
+	rows := g.rows[g.scroll:]
+	if len(rows) > shown {
+		rows = rows[:shown]
+	}
+	g.panel(0, y, debugTreeW, int32(len(rows))*g.lineH+2*debugPad)
+	y += debugPad
+	for i := range rows {
+		row := &rows[i]
+		row.y = y
+		c := sdl.Color{R: 0xc0, G: 0xc0, B: 0xc0, A: 0xff}
+		if row.dob == g.selected {
+			c = sdl.Color{R: 0xff, G: 0xe0, B: 0x40, A: 0xff}
+		}
+		x := debugPad + int32(row.depth*debugIndent)
+		if row.dob.dobs != nil && row.dob.dobs.Len() > 0 {
+			arrow := "+"
+			if g.expanded[row.dob.id] {
+				arrow = "-"
+			}
+			g.text(x, y, arrow, c)
+		}
+		g.text(x+debugIndent, y, debugLabel(row.dob), c)
+		y += g.lineH
+	}
+	g.rows = rows
+}
+
+// treeRows adds rows for d and its expanded descendants
+func (g *Debug) treeRows(d *Dob, depth int) {
+	g.rows = append(g.rows, debugRow{dob: d, depth: depth})
+	if !g.expanded[d.id] {
+		return
+	}
+	d.dobs.Range(func(id int64, b *Dob) bool {
+		if b != nil {
+			g.treeRows(b, depth+1)
+		}
+		return true
+	})
+}
+
+// debugLabel names d for the tree
+func debugLabel(d *Dob) string {
+	var kind string
+	switch {
+	case d == d.Stage.Root:
+		kind = "root"
+	case d.txt != "":
+		kind = fmt.Sprintf("%q", d.txt)
+		if len(kind) > 24 {
+			kind = kind[:23] + `..."`
+		}
+	case d.Painter != nil:
+		kind = strings.TrimPrefix(fmt.Sprintf("%T", d.Painter), "*gas.")
+	case d.Texture != nil && d.Texture.path != "":
+		kind = filepath.Base(d.Texture.path)
+	case d.FillC.A > 0:
+		kind = "rect"
+	default:
+		kind = "dob"
+	}
+	for _, l := range d.Stage.layers {
+		if &l.Dob == d {
+			kind = "layer " + l.Name
+		}
+	}
+	label := fmt.Sprintf("#%d %s", d.id, kind)
+	if d.dobs != nil && d.dobs.Len() > 0 {
+		label += fmt.Sprintf(" (%d)", d.dobs.Len())
+	}
+	if d.Hidden {
+		label += " hidden"
+	}
+	return label
+}
+
+// propsPaint draws the properties and running ans of the selected dob
+func (g *Debug) propsPaint() {
+	d := g.selected
+	if d == nil {
+		return
+	}
+	lines := []string{
+		debugLabel(d),
+		fmt.Sprintf("pos %.1f, %.1f  size %d x %d", d.Px, d.Py, d.D[0], d.D[1]),
+		fmt.Sprintf("scale %.2f  zoom %.2f  angle %.1f", d.Scale, d.zoom, d.angle),
+		fmt.Sprintf("z %d  pivot %.2f, %.2f  flip %d", d.Z, d.Pivot[0], d.Pivot[1], d.Flip),
+		fmt.Sprintf("fill #%02x%02x%02x%02x  blend %d", d.FillC.R, d.FillC.G, d.FillC.B, d.FillC.A, d.Blend),
+		fmt.Sprintf("hidden %t  culled %t  clip %t  cache %t", d.Hidden, d.culled, d.Clip, d.Cache != nil),
+		fmt.Sprintf("painted at %d, %d  %d x %d", d.dst.X, d.dst.Y, d.dst.W, d.dst.H),
+	}
+	if len(d.anSet) > 0 {
+		lines = append(lines, fmt.Sprintf("ans %d", len(d.anSet)))
+	}
+	for _, an := range d.anSet {
+		lines = append(lines, "  "+anLabel(an, d.Stage))
+	}
+	s := g.stage
+	x := int32(s.view.W) - debugPropsW
+	g.panel(x, 0, debugPropsW, int32(len(lines))*g.lineH+2*debugPad)
+	y := int32(debugPad)
+	for _, line := range lines {
+		g.text(x+debugPad, y, line, sdl.Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+		y += g.lineH
+	}
+}
+
+// anLabel describes an An and its progress
+func anLabel(an An, s *Stage) string {
+	label := strings.TrimPrefix(fmt.Sprintf("%T", an), "*gas.")
+	if b, ok := an.(interface{ base() *BaseAn }); ok {
+		a := b.base()
+		if a.Duration > 0 && a.StartTick > 0 {
+			pct := float64(s.tickOf(a.dob)-a.StartTick) * float64(s.DurationPerTick) / float64(a.Duration)
+			label += fmt.Sprintf(" %d%% of %s", int(math.Min(pct, 1)*100), time.Duration(a.Duration))
+		} else if a.Duration > 0 {
+			label += " of " + time.Duration(a.Duration).String()
+		}
+	}
+	if n := anCount(an) - 1; n > 0 {
+		label += fmt.Sprintf(" then %d", n)
+	}
+	return label
+}
+
+// boxesPaint outlines the painted dobs with their pivots and ids
+func (g *Debug) boxesPaint(boxes []debugBox) {
+	r := g.stage.view.Renderer
+	var pts [5]sdl.Point
+	for _, b := range boxes {
+		ox, oy := float64(b.vp.X), float64(b.vp.Y)
+		cx := ox + float64(b.dst.X) + float64(b.dst.W)/2
+		cy := oy + float64(b.dst.Y) + float64(b.dst.H)/2
+		sin, cos := math.Sincos(b.angle * math.Pi / 180)
+		hw, hh := float64(b.dst.W)/2, float64(b.dst.H)/2
+		for i, c := range [4][2]float64{{-hw, -hh}, {hw, -hh}, {hw, hh}, {-hw, hh}} {
+			pts[i] = sdl.Point{X: int32(cx + c[0]*cos - c[1]*sin), Y: int32(cy + c[0]*sin + c[1]*cos)}
+		}
+		pts[4] = pts[0]
+		r.SetDrawColor(0x40, 0xff, 0xff, 0xc0)
+		r.DrawLines(pts[:])
+		px, py := int32(ox+float64(b.pivotX)), int32(oy+float64(b.pivotY))
+		r.SetDrawColor(0xff, 0x40, 0xff, 0xff)
+		r.FillRect(&sdl.Rect{X: px - 2, Y: py - 2, W: 5, H: 5})
+		g.text(pts[0].X+2, pts[0].Y+1, fmt.Sprintf("%d", b.id), sdl.Color{R: 0x40, G: 0xff, B: 0xff, A: 0xff})
+	}
+}
+
+// panel darkens a rect for text
+func (g *Debug) panel(x, y, w, h int32) {
+	r := g.stage.view.Renderer
+	r.SetDrawColor(0, 0, 0, 0xb0)
+	r.FillRect(&sdl.Rect{X: x, Y: y, W: w, H: h})
+}
+
+// text draws ASCII s at x, y
+func (g *Debug) text(x, y int32, s string, c sdl.Color) {
+	r := g.stage.view.Renderer
+	g.texture.SetColorMod(c.R, c.G, c.B)
+	for _, ch := range s {
+		if ch >= 128 {
+			ch = '?'
+		}
+		src := &g.glyphs[ch]
+		if src.W == 0 {
+			continue
+		}
+		dst := sdl.Rect{X: x, Y: y, W: src.W, H: src.H}
+		r.Copy(g.texture, src, &dst)
+		x += src.W
+	}
+}
+
+// atlas renders the printable ASCII glyphs of the font to one texture
+func (g *Debug) atlas() bool {
+	var surfaces [128]*sdl.Surface
+	var w, h int32
+	for ch := ' '; ch < 127; ch++ {
+		surf, err := g.font.RenderUTF8Blended(string(ch), sdl.Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+		if err != nil {
+			continue
+		}
+		surfaces[ch] = surf
+		w += surf.W
+		if surf.H > h {
+			h = surf.H
+		}
+	}
+	defer func() {
+		for _, surf := range surfaces {
+			if surf != nil {
+				surf.Free()
+			}
+		}
+	}()
+	atlas, err := sdl.CreateRGBSurfaceWithFormat(0, w, h, 32, uint32(sdl.PIXELFORMAT_ARGB8888))
+	if err != nil {
+		return false
+	}
+	defer atlas.Free()
+	x := int32(0)
+	for ch, surf := range surfaces {
+		if surf == nil {
+			continue
+		}
+		// copy the glyph alpha as is
+		surf.SetBlendMode(sdl.BLENDMODE_NONE)
+		g.glyphs[ch] = sdl.Rect{X: x, Y: 0, W: surf.W, H: surf.H}
+		surf.Blit(nil, atlas, &sdl.Rect{X: x, Y: 0, W: surf.W, H: surf.H})
+		x += surf.W
+	}
+	g.texture, err = g.stage.view.Renderer.CreateTextureFromSurface(atlas)
+	if err != nil {
+		return false
+	}
+	g.texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+	g.lineH = int32(g.font.LineSkip())
+	return true
+}
+
+// textureBytes estimates the memory of the textures for r
+func (a *Assets) textureBytes(r *sdl.Renderer) (n int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, t := range a.textures[r] {
+		n += int64(t.W) * int64(t.H) * 4
+	}
+	return n
+}
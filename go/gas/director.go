@@ -0,0 +1,381 @@
+package gas
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// TransitionKind is how one scene gives way to the next
+type TransitionKind int
+
+const (
+	TransCrossfade TransitionKind = iota // the next scene fades in over the last
+	TransSlide                           // the next scene pushes the last off the view in the direction Dir
+	TransWipe                            // an edge moving in the direction Dir uncovers the next scene
+	TransIris                            // a circle growing from the center uncovers the next scene
+)
+
+// Transition animates the change from one scene to the next. Both scenes tick and paint until it completes.
+type Transition struct {
+	Kind     TransitionKind
+	Dir      [2]float32 // unit direction for slides and wipes. eg. {-1, 0} moves left
+	Duration time.Duration
+	Easer    Ease
+}
+
+// Crossfade yields a Transition that fades in the next scene
+func Crossfade(duration time.Duration) *Transition {
+	return &Transition{Kind: TransCrossfade, Duration: duration, Easer: EaseInOutSin}
+}
+
+// Slide yields a Transition that moves the scenes in direction dx, dy. eg. Slide(-1, 0, time.Second) moves left
+func Slide(dx, dy float32, duration time.Duration) *Transition {
+	return &Transition{Kind: TransSlide, Dir: [2]float32{dx, dy}, Duration: duration, Easer: EaseInOutSin}
+}
+
+// Wipe yields a Transition with an edge moving in direction dx, dy
+func Wipe(dx, dy float32, duration time.Duration) *Transition {
+	return &Transition{Kind: TransWipe, Dir: [2]float32{dx, dy}, Duration: duration, Easer: EaseNone}
+}
+
+// Iris yields a Transition with a growing circle
+func Iris(duration time.Duration) *Transition {
+	return &Transition{Kind: TransIris, Duration: duration, Easer: EaseInOutSin}
+}
+
+// transition is a Transition in progress
+type transition struct {
+	*Transition
+	from  *Stage // outgoing scene
+	start int32  // director tick of the first frame
+	to    *Stage // incoming scene
+}
+
+// Director runs the loop for a View and a stack of scenes (Stages) on it:
+// intro, menu, game, game over... Only the top scene ticks, paints and hears input,
+// except during transitions, when the outgoing scene keeps going too.
+type Director struct {
+	DurationPerTick int64
+	Recorder        *Recorder   // captures each frame while recording. see Record
+	Times           *FrameTimes // collects frame times when set. see TimesOn
+	allocs          uint64
+	dos             []func() // queued by Do. guarded by dosMu
+	dosMu           sync.Mutex
+	dosRun          []func()   // scratch for the dos of this frame
+	inspector       *Inspector // serves commands between frames. see Inspect
+	logMallocsLast  uint64
+	logTickLast     int32
+	memStats        runtime.MemStats
+	phaseDur        [phaseN]time.Duration // time in each phase this frame
+	rects           []sdl.Rect            // scratch rects for compositing
+	running         bool
+	scenes          []*Stage
+	targets         [2]*sdl.Texture // render targets for the scenes of a transition
+	tick            int32
+	trans           *transition
+	view            *View
+}
+
+// MakeDirector returns a Director with no scenes for v
+func MakeDirector(v *View) *Director {
+	return &Director{view: v}
+}
+
+// Top returns the scene on top of the stack or nil
+func (d *Director) Top() *Stage {
+	if len(d.scenes) == 0 {
+		return nil
+	}
+	return d.scenes[len(d.scenes)-1]
+}
+
+// Push covers the top scene with s. The covered scene freezes until it is on top again.
+// A nil t cuts straight to s.
+func (d *Director) Push(s *Stage, t *Transition) {
+	from := d.Top()
+	d.scenes = append(d.scenes, s)
+	d.transStart(from, s, t)
+}
+
+// Pop removes the top scene and reveals the one beneath. Popping the last scene ends Play.
+// Popped scenes keep their dobs, so they can be pushed again. Clear their Root when done with them.
+func (d *Director) Pop(t *Transition) {
+	from := d.Top()
+	if from == nil {
+		return
+	}
+	d.scenes = d.scenes[:len(d.scenes)-1]
+	d.transStart(from, d.Top(), t)
+}
+
+// Replace swaps the top scene for s
+func (d *Director) Replace(s *Stage, t *Transition) {
+	from := d.Top()
+	if from != nil {
+		d.scenes = d.scenes[:len(d.scenes)-1]
+	}
+	d.scenes = append(d.scenes, s)
+	d.transStart(from, s, t)
+}
+
+// transStart starts a transition, finishing any transition in progress first
+func (d *Director) transStart(from, to *Stage, t *Transition) {
+	d.transEnd()
+	if to != nil {
+		to.DurationPerTick = d.DurationPerTick
+		if to.view.W != to.Root.D[0] || to.view.H != to.Root.D[1] {
+			to.Resize(to.view.W, to.view.H)
+		}
+	}
+	if t == nil || from == nil || to == nil || t.Duration <= 0 {
+		return
+	}
+	d.trans = &transition{Transition: t, from: from, start: d.tick, to: to}
+}
+
+// transEnd finishes the transition in progress, if any
+func (d *Director) transEnd() {
+	d.trans = nil
+}
+
+// Stop ends Play after the current frame
+func (d *Director) Stop() {
+	d.running = false
+}
+
+// Play runs the loop at fps until the user quits, Stop is called or the last scene pops
+func (d *Director) Play(fps int) {
+	msPerFrame := int64(1000.0 / fps)
+	d.DurationPerTick = msPerFrame * int64(time.Millisecond)
+	for _, s := range d.scenes {
+		s.DurationPerTick = d.DurationPerTick
+	}
+
+	// loop until the user quits
+	d.running = true
+	for d.running && len(d.scenes) > 0 {
+		frame := d.phaseStart(phaseFrame)
+		d.Frame()
+		present := d.phaseStart(phasePresent)
+		d.view.Renderer.Present()
+		d.phaseEnd(phasePresent, present)
+		d.poll()
+		d.phaseEnd(phaseFrame, frame)
+		d.phaseCommit(phasePresent)
+		d.phaseCommit(phaseFrame)
+		sdl.Delay(uint32(msPerFrame))
+		if int(d.tick-d.logTickLast) >= fps {
+			d.logTickLast = d.tick
+			runtime.ReadMemStats(&d.memStats)
+			d.allocs = (d.memStats.Mallocs - d.logMallocsLast) / uint64(fps)
+			d.logMallocsLast = d.memStats.Mallocs
+		}
+	}
+}
+
+// Do runs fn on the game loop at the start of the next frame, before any scene ticks.
+// Safe to call from any goroutine, eg. to change the dob tree from a timer.
+func (d *Director) Do(fn func()) {
+	d.dosMu.Lock()
+	d.dos = append(d.dos, fn)
+	d.dosMu.Unlock()
+}
+
+// dosDrain runs the functions queued by Do
+func (d *Director) dosDrain() {
+	d.dosMu.Lock()
+	d.dos, d.dosRun = d.dosRun[:0], d.dos
+	d.dosMu.Unlock()
+	for i, fn := range d.dosRun {
+		d.dosRun[i] = nil
+		fn()
+	}
+}
+
+// Frame ticks and paints the running scenes, compositing them during a transition.
+// Play calls it once per frame. Call it directly to drive the director from another loop.
+// Runs the functions queued by Do, ticks the view Audio, captures the frame while recording and serves the Inspector.
+func (d *Director) Frame() {
+	d.dosDrain()
+	if d.view.Audio != nil {
+		d.view.Audio.Tick(d.tick+1, d.DurationPerTick)
+	}
+	d.frame()
+	d.phaseCommit(phaseTick)
+	d.phaseCommit(phasePaint)
+	if d.Recorder != nil {
+		d.Recorder.capture(d)
+	}
+	if d.inspector != nil {
+		d.inspector.drain()
+	}
+}
+
+// frame implements Frame
+func (d *Director) frame() {
+	d.tick++
+	t := d.trans
+	if t != nil && !(d.targetSet(0) && d.targetSet(1)) {
+		// no render targets. cut to the next scene before ticking either
+		d.view.Renderer.SetRenderTarget(nil)
+		d.transEnd()
+		t = nil
+	}
+	if t == nil {
+		s := d.Top()
+		if s == nil {
+			return
+		}
+		s.allocs = d.allocs
+		tick := d.phaseStart(phaseTick)
+		s.Tick(s.tick + 1)
+		d.phaseEnd(phaseTick, tick)
+		paint := d.phaseStart(phasePaint)
+		s.Paint()
+		s.Pool.flush()
+		d.phaseEnd(phasePaint, paint)
+		return
+	}
+
+	for i, s := range [2]*Stage{t.from, t.to} {
+		s.allocs = d.allocs
+		tick := d.phaseStart(phaseTick)
+		s.Tick(s.tick + 1)
+		d.phaseEnd(phaseTick, tick)
+		d.targetSet(i) // made above, so this only binds it
+		paint := d.phaseStart(phasePaint)
+		s.Paint()
+		s.Pool.flush()
+		d.phaseEnd(phasePaint, paint)
+	}
+	paint := d.phaseStart(phasePaint)
+	defer d.phaseEnd(phasePaint, paint)
+	r := d.view.Renderer
+	r.SetRenderTarget(nil)
+
+	pct := float32(int64(d.tick-t.start)*d.DurationPerTick) / float32(t.Duration)
+	if pct >= 1 {
+		pct = 1
+	}
+	d.composite(t, t.Easer(pct))
+	if pct == 1 {
+		d.transEnd()
+	}
+}
+
+// targetSet renders to target i, making it if needed. Returns false if the renderer cannot.
+func (d *Director) targetSet(i int) bool {
+	r := d.view.Renderer
+	w, h := d.view.W, d.view.H
+	tex := d.targets[i]
+	if tex != nil {
+		if _, _, tw, th, err := tex.Query(); err != nil || tw != w || th != h {
+			tex.Destroy()
+			tex = nil
+		}
+	}
+	if tex == nil {
+		var err error
+		tex, err = r.CreateTexture(uint32(sdl.PIXELFORMAT_ARGB8888), sdl.TEXTUREACCESS_TARGET, w, h)
+		if err != nil {
+			d.targets[i] = nil
+			return false
+		}
+		tex.SetBlendMode(sdl.BLENDMODE_BLEND)
+		d.targets[i] = tex
+	}
+	return r.SetRenderTarget(tex) == nil
+}
+
+// composite draws the outgoing and incoming scenes for a transition at eased progress pct
+func (d *Director) composite(t *transition, pct float32) {
+	r := d.view.Renderer
+	from, to := d.targets[0], d.targets[1]
+	w, h := d.view.W, d.view.H
+	r.SetDrawColor(0, 0, 0, 0xff)
+	r.Clear()
+
+	switch t.Kind {
+	case TransCrossfade:
+		r.Copy(from, nil, nil)
+		to.SetAlphaMod(uint8(0xff * pct))
+		r.Copy(to, nil, nil)
+		to.SetAlphaMod(0xff)
+	case TransSlide:
+		dx, dy := t.Dir[0]*float32(w), t.Dir[1]*float32(h)
+		d.rects = append(d.rects[:0],
+			sdl.Rect{X: int32(dx * pct), Y: int32(dy * pct), W: w, H: h},
+			sdl.Rect{X: int32(dx * (pct - 1)), Y: int32(dy * (pct - 1)), W: w, H: h})
+		r.Copy(from, nil, &d.rects[0])
+		r.Copy(to, nil, &d.rects[1])
+	case TransWipe:
+		// the uncovered region starts at the edge behind the wipe
+		x0, x1, y0, y1 := float32(0), float32(w), float32(0), float32(h)
+		switch {
+		case t.Dir[0] > 0:
+			x1 = pct * float32(w)
+		case t.Dir[0] < 0:
+			x0 = (1 - pct) * float32(w)
+		case t.Dir[1] > 0:
+			y1 = pct * float32(h)
+		case t.Dir[1] < 0:
+			y0 = (1 - pct) * float32(h)
+		}
+		d.rects = append(d.rects[:0], sdl.Rect{X: int32(x0), Y: int32(y0), W: int32(x1 - x0), H: int32(y1 - y0)})
+		r.Copy(from, nil, nil)
+		r.Copy(to, &d.rects[0], &d.rects[0])
+	case TransIris:
+		// copy the circle one row at a time
+		r.Copy(from, nil, nil)
+		cx, cy := float32(w)/2, float32(h)/2
+		rad := pct * float32(math.Hypot(float64(cx), float64(cy)))
+		d.rects = d.rects[:0]
+		for y := int32(0); y < h; y++ {
+			dy := float32(y) + .5 - cy
+			if dy*dy >= rad*rad {
+				continue
+			}
+			dx := float32(math.Sqrt(float64(rad*rad - dy*dy)))
+			x0, x1 := int32(cx-dx), int32(cx+dx)
+			d.rects = append(d.rects, sdl.Rect{X: x0, Y: y, W: x1 - x0, H: 1})
+		}
+		for i := range d.rects {
+			r.Copy(to, &d.rects[i], &d.rects[i])
+		}
+	}
+}
+
+// poll routes events to the top scene and handles quits, resizes and gamepads
+func (d *Director) poll() {
+	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+		switch e := event.(type) {
+		case *sdl.QuitEvent:
+			d.view.Log.Info("quit")
+			d.running = false
+		case *sdl.WindowEvent:
+			if d.view.windowEvent(e) {
+				for _, s := range d.scenes {
+					s.Resize(d.view.W, d.view.H)
+				}
+			}
+		case *sdl.ControllerDeviceEvent:
+			// pads connect for every scene, not just the one on top when they do
+			padEvent(e)
+		case *sdl.RenderEvent:
+			if e.Type == sdl.RENDER_DEVICE_RESET {
+				// the renderer lost its textures
+				if err := d.view.Assets.TexturesReload(d.view.Renderer); err != nil {
+					d.view.Log.Error("textures reload", "err", err)
+				}
+			}
+		default:
+			if s := d.Top(); s != nil {
+				s.Input.Handle(event)
+			}
+		}
+	}
+}
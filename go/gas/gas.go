@@ -2,25 +2,26 @@
 package gas
 
 import (
-	"fmt"
+	"errors"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/goradd/maps"
 	"github.com/veandco/go-sdl2/img"
-	"github.com/veandco/go-sdl2/mix"
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/ttf"
 )
 
 var anID int64
 var dobID int64
-var dobsPainted int64
 
 // Init initializes sdl dependencies for gas. Should be the first call when using the framework.
+// Sounds play silently if there is no audio device. See AudioShared.
+// Logs through LogShared, so set that first to use another Logger.
 func Init() (err error) {
 	// init sdl
-	err = sdl.Init(sdl.INIT_AUDIO | sdl.INIT_EVENTS | sdl.INIT_TIMER | sdl.INIT_VIDEO)
+	err = sdl.Init(sdl.INIT_EVENTS | sdl.INIT_GAMECONTROLLER | sdl.INIT_TIMER | sdl.INIT_VIDEO)
 	if err != nil {
 		return err
 	}
@@ -32,151 +33,201 @@ func Init() (err error) {
 	if err != nil {
 		return err
 	}
-	return mix.OpenAudio(mix.DEFAULT_FREQUENCY, mix.DEFAULT_FORMAT, mix.DEFAULT_CHANNELS, 4096)
+	// play silently without an audio device
+	var backend AudioBackend = MakeNullBackend()
+	if mixer, err := MakeMixBackend(); err == nil {
+		backend = mixer
+	} else {
+		LogShared.Warn("no audio", "err", err)
+	}
+	AudioShared = MakeAudio(backend)
+	return nil
 }
 
 // Destroy quits sdl dependencies when clients no longer need gas. Call it with a defer after .Init
 func Destroy() {
+	padsClose()
 	sdl.Quit()
 }
 
 // View provides context for all DOBs (most notably the renderer)
 type View struct {
-	H        int32
-	Renderer *sdl.Renderer
-	Title    string
-	W        int32
-	fonts    map[string]*ttf.Font
-	sounds   map[string]*Wav
-	textures map[string]*Texture
-	window   *sdl.Window
+	Assets     *Assets // caches fonts, sounds and textures. defaults to AssetsShared. set before Init
+	Audio      *Audio  // plays sounds and music. defaults to AudioShared. set before Init
+	H          int32
+	HighDPI    bool       // render at full resolution on high-DPI displays. set before Init
+	Log        Logger     // defaults to LogShared. set before Init
+	Mode       WindowMode // set before Init or change with ModeSet
+	Renderer   *sdl.Renderer
+	Resizable  bool // lets the user resize the window. set before Init
+	Title      string
+	W          int32
+	logical    bool // the size is fixed. see LogicalSize
+	noGeometry bool // the renderer does not support RenderGeometry
+	window     *sdl.Window
 }
 
 // MakeView  returns a gas.View which maps to and sdl window. Multiples ok.
+// For a resizable, high-DPI or fullscreen window, set up a View and call Init instead.
 func MakeView(w, h int32, title string) (view *View, err error) {
-	view = &View{W: w, H: h, Title: "Frogger"}
+	view = &View{W: w, H: h, Title: title}
 	return view, view.Init()
 }
 
-// Init sets up a new view (HostOS window)
-// TODO share textures, sounds, and fonts between views.
+// Init sets up a new view (HostOS window).
+// Views share fonts, sounds and images through Assets.
 func (v *View) Init() (err error) {
-	v.window, v.Renderer, err = sdl.CreateWindowAndRenderer(v.W, v.H, sdl.WINDOW_SHOWN)
+	if v.Assets == nil {
+		v.Assets = AssetsShared
+	}
+	if v.Audio == nil {
+		v.Audio = AudioShared
+	}
+	if v.Log == nil {
+		v.Log = LogShared
+	}
+	v.window, v.Renderer, err = sdl.CreateWindowAndRenderer(v.W, v.H, v.windowFlags())
+	if err != nil {
+		return err
+	}
 	v.window.SetTitle(v.Title)
-	v.textures = make(map[string]*Texture)
-	v.sounds = make(map[string]*Wav)
-	v.fonts = make(map[string]*ttf.Font)
+	v.sizeToWindow()
 	return
 }
 
-// Destroy releases the view window
+// Destroy releases the view window and its textures
 func (v *View) Destroy() {
+	v.Assets.TexturesRm(v.Renderer)
+	v.Renderer.Destroy()
 	v.window.Destroy()
 }
 
-func (v *View) TextureLoad(path string) (texture *Texture, err error) {
-	var ok bool
-	texture, ok = v.textures[path]
-	if !ok {
-		texture = &Texture{}
-		texture.SDLTexture, err = img.LoadTexture(v.Renderer, path)
-		if err != nil {
-			err = fmt.Errorf("could not load texture at %s: %v", path, err)
-			fmt.Println(err.Error())
-			return nil, err
-		}
-
-		_, _, texture.W, texture.H, err = texture.SDLTexture.Query()
-		if err != nil {
-			err = fmt.Errorf("could not query texture at %s: %v", path, err)
-			fmt.Println(err.Error())
-			return nil, err
-		}
-		v.textures[path] = texture
-	}
-	return
+// TextureLoad returns the image at path as a texture for the view renderer
+func (v *View) TextureLoad(path string) (*Texture, error) {
+	return v.Assets.TextureLoad(v.Renderer, path)
 }
 
+// SoundLoad returns the sound at path. Sounds are shared by all views on the Assets.
 func (v *View) SoundLoad(path string) (*Wav, error) {
-	snd, ok := v.sounds[path]
-	if !ok {
-		wav, err := mix.LoadWAV(path)
-		if err != nil {
-			err = fmt.Errorf("could not load sound at %s: %v", path, err)
-			return nil, err
-		}
-		snd = &Wav{View: v, Wav: wav}
-		v.sounds[path] = snd
-	}
-	return snd, nil
+	return v.Assets.SoundLoad(path)
+}
+
+// MusicLoad returns the music at path. Music is shared by all views on the Assets.
+func (v *View) MusicLoad(path string) (*Music, error) {
+	return v.Assets.MusicLoad(path)
 }
 
+// FontLoad returns the font at path in size pts. Fonts are shared by all views on the Assets.
 func (v *View) FontLoad(path string, size int) (font *ttf.Font, err error) {
-	key := fmt.Sprintf("%s-%d", path, size)
-	var ok bool
-	font, ok = v.fonts[key]
-	if !ok {
-		font, err = ttf.OpenFont(path, size)
-		if err != nil {
-			return
-		}
-		v.fonts[key] = font
-	}
-	return font, nil
+	return v.Assets.FontLoad(path, size)
 }
 
 // Stage is the root of the display tree
 type Stage struct {
 	DurationPerTick int64
+	allocs          uint64 // heap allocations per frame over the last second
+	anChained       []An   // scratch for Dob.Tick
 	view            *View
 	BGColor         sdl.Color
+	Cameras         []*Camera // render the stage through these. see CameraAdd
+	Input           *Input    // keyboard, pointer and gamepad state, sampled each tick
+	Log             Logger    // defaults to the view Log
+	Paused          bool      // skips ticks, freezing Ans and cameras. input still flows. see Step
+	Pool            *Pool     // recycles dobs and ans when set. see MakePool
 	Root            *Dob
-	logTickLast     int32
+	layers          []*Layer
+	paintDstF       sdl.FRect // scratch rects for painting so cgo calls do not allocate
+	paintSrc        sdl.Rect
+	clips           []clipState // clip rects to restore as Clip dobs finish painting
+	debug           *Debug      // overlay. see DebugOn
+	pointer         pointer     // hover and drag state for pointer events
+	safe            Insets      // see SafeSet
+	shots           []string    // paths queued by Screenshot
+	stats           Stats       // for the frame in progress
+	statsLast       Stats       // for the last complete frame
+	statsMu         sync.Mutex  // guards statsLast
+	steps           int         // ticks to run while Paused
+	tick            int32
+	xf              xf // maps stage coordinates to the render target while painting
 }
 
 // MakeStage returns a new rendering context.
-// TODO test that multiple stages work with one view.
+// Several stages can share a view as scenes of a Director.
 func MakeStage(v *View) (s *Stage, err error) {
-	s = &Stage{view: v}
+	s = &Stage{Log: v.Log, view: v}
+	if s.Log == nil {
+		s.Log = LogShared
+	}
 	s.Root = &Dob{Stage: s, zoom: 1}
+	s.Root.dob = s.Root // so stage-wide Ans (eg. music fades) can run on the root
 	s.Root.D[0] = v.W
 	s.Root.D[1] = v.H
 	s.Root.Px = float32(v.W / 2)
 	s.Root.Py = float32(v.H / 2)
 	s.Root.FillC = SDLC(0x00000000)
 	s.Root.Scale = 1
+	s.Input = MakeInput(v)
+	s.Input.On(s.pointerEvent)
+	s.xf = xfIdentity(v.W, v.H)
 	dobID++
 	return
 }
 
-// Play starts the animation / rendering loop
+// Play starts the animation / rendering loop with the stage as the only scene. See Director.
 func (s *Stage) Play(fps int) {
-	msPerFrame := int64(1000.0 / fps)
-	s.DurationPerTick = msPerFrame * int64(time.Millisecond)
-
-	// loop until the user quits
-	running := true
-	var tick int32 = 1
-	for running {
-		dobsPainted = 0
-		s.view.Renderer.SetDrawColor(s.BGColor.R, s.BGColor.G, s.BGColor.B, s.BGColor.A)
-		s.view.Renderer.Clear()
-		s.Root.Tick(tick)
-		s.Root.Paint()
-		s.view.Renderer.Present()
-		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-			switch event.(type) {
-			case *sdl.QuitEvent:
-				println("Quit")
-				running = false
-			}
+	d := MakeDirector(s.view)
+	d.Push(s, nil)
+	d.Play(fps)
+}
+
+// Tick samples input, runs all Ans on the stage (except in paused layers), then updates cameras.
+// Resizes the stage first if the view changed size, eg. through LogicalSize.
+func (s *Stage) Tick(tick int32) {
+	if s.view.W != s.Root.D[0] || s.view.H != s.Root.D[1] {
+		s.Resize(s.view.W, s.view.H)
+	}
+	if s.Paused {
+		if s.steps == 0 {
+			s.Input.Sample(tick)
+			return
 		}
-		sdl.Delay(uint32(msPerFrame))
-		if int(tick-s.logTickLast) >= fps {
-			s.logTickLast = tick
-			fmt.Printf("dobs painted: %d\n", dobsPainted)
+		s.steps--
+	}
+	s.tick = tick
+	s.Input.Sample(tick)
+	s.Root.Tick(tick)
+	s.layersTick(tick)
+	for _, c := range s.Cameras {
+		c.Tick(tick)
+		c.update()
+	}
+}
+
+// Step runs n ticks while Paused
+func (s *Stage) Step(n int) {
+	s.steps += n
+}
+
+// Paint clears the view and paints the stage, through cameras if there are any
+func (s *Stage) Paint() {
+	s.stats = Stats{Allocs: s.allocs, Tick: s.tick}
+	s.view.Renderer.SetDrawColor(s.BGColor.R, s.BGColor.G, s.BGColor.B, s.BGColor.A)
+	s.view.Renderer.Clear()
+	if len(s.Cameras) == 0 {
+		s.layersPaint(xfIdentity(s.view.W, s.view.H))
+	} else {
+		for _, c := range s.Cameras {
+			c.paint()
 		}
-		tick++
+	}
+	s.statsMu.Lock()
+	s.statsLast = s.stats
+	s.statsMu.Unlock()
+	if len(s.shots) > 0 {
+		s.shotsSave()
+	}
+	if s.debug != nil {
+		s.debug.paint()
 	}
 }
 
@@ -185,21 +236,52 @@ func (s *Stage) Play(fps int) {
 // Supports animation, z-layer nesting, etc.
 type Dob struct {
 	BaseAn
-	angle   float64                     // angle to rotate
-	D       [2]int32                    // dim
-	FillC   sdl.Color                   // color to render if texture is nil
-	Px      float32                     // posX
-	Py      float32                     // posY
-	Scale   float32                     // default scale of hi-rez text and graphics
-	Stage   *Stage                      // provides access to context and renderer
-	Texture *Texture                    // texture to render
-	TxtOutC sdl.Color                   // color of the text outline
-	TxtOutW int                         // outline width
-	ctx     *Dob                        // the dob to which this dob is a child
-	dobs    *maps.SliceMap[int64, *Dob] // children of this dob in the render order
-	txt     string                      // actual text rendered in this dob
-	txtFont *ttf.Font                   // text font
-	zoom    float32                     // current zoom/scaling factor
+	Blend          Blend                       // how the dob combines with what paints beneath it
+	Cache          *Cache                      // paints the subtree from an offscreen texture. see CacheOn
+	Clip           bool                        // confines the subtree to the rect of the dob (unrotated)
+	angle          float64                     // angle to rotate
+	D              [2]int32                    // dim
+	FillC          sdl.Color                   // color to render if texture is nil
+	Flip           sdl.RendererFlip            // mirrors the texture. sdl.FLIP_HORIZONTAL, sdl.FLIP_VERTICAL or both
+	Hidden         bool                        // skips painting and hits for this dob and its children
+	HitAlpha       uint8                       // if set, pointer hits need texture pixels at least this opaque
+	Layout         Layout                      // positions the children. see HStack, VStack and Grid
+	Mask           *Dob                        // the subtree paints only where this dob is opaque. see MaskSpawn
+	OnPointerDown  PointerHandler              // a button went down on the dob or a descendant
+	OnPointerDrag  PointerHandler              // the pointer moved after a Down on the dob or a descendant
+	OnPointerEnter PointerHandler              // the pointer moved onto the dob or a descendant. does not bubble
+	OnPointerLeave PointerHandler              // the pointer moved off the dob and its descendants. does not bubble
+	OnPointerUp    PointerHandler              // a button went up on the dob or a descendant
+	Px             float32                     // posX
+	Py             float32                     // posY
+	Painter        Painter                     // renders the dob in place of Texture and FillC
+	Pivot          [2]float32                  // spin and zoom around this point, in fractions of the size off center. {0, .5} is the bottom middle
+	Scale          float32                     // default scale of hi-rez text and graphics
+	Stage          *Stage                      // provides access to context and renderer
+	Texture        *Texture                    // texture to render
+	TxtOutC        sdl.Color                   // color of the text outline
+	TxtOutW        int                         // outline width
+	YSort          bool                        // paint children with equal Z from top (low Py) to bottom
+	anchor         *Anchor                     // pins the dob to its ctx. see Dob.Anchor
+	cacheLast      cacheState                  // paint state as of the last check by an ancestor Cache
+	Z              int                         // paint order among siblings. lower paints first. ties paint in insertion order
+	ctx            *Dob                        // the dob to which this dob is a child
+	dobs           *maps.SliceMap[int64, *Dob] // children of this dob in insertion order
+	culled         bool                        // outside the render target as of the last Paint
+	dirty          bool                        // paint state changed in the last Paint
+	dst            sdl.Rect                    // where the dob painted as of the last Paint
+	mask           *mask                       // offscreen rendering for Mask
+	paintQ         []*Dob                      // children of this dob in paint order. rebuilt each Paint
+	painted        paintState                  // paint state as of the last Paint
+	released       bool                        // exited or cleared by its ctx. reset when the pool recycles the dob
+	txt            string                      // actual text rendered in this dob
+	txtFont        *ttf.Font                   // text font
+	zoom           float32                     // current zoom/scaling factor
+}
+
+// Painter takes over rendering of a dob, eg. to batch many quads in one call
+type Painter interface {
+	Paint(d *Dob)
 }
 
 // Tick
@@ -209,14 +291,38 @@ type Dob struct {
 //
 // TODO convert anSet to a SliceMap for determinism.
 func (d *Dob) Tick(tick int32) {
+	// Ans chained to completed Ans start on the same tick, so chains keep time
+	chained := d.Stage.anChained[:0]
 	for ID, an := range d.anSet {
 		if an.Tick(tick) {
 			delete(d.anSet, ID)
-			for nID, nAn := range an.AnSet() {
-				d.anSet[nID] = nAn
+			for _, nAn := range an.AnSet() {
+				chained = append(chained, nAn)
 			}
+			d.Stage.Pool.anPut(an, false)
 		}
 	}
+	for i := 0; i < len(chained); i++ {
+		an := chained[i]
+		chained[i] = nil
+		if d.released {
+			// an An exited the dob. what it chained never starts
+			d.Stage.Pool.anPut(an, true)
+			continue
+		}
+		if an.Tick(tick) {
+			for _, nAn := range an.AnSet() {
+				chained = append(chained, nAn)
+			}
+			d.Stage.Pool.anPut(an, false)
+		} else {
+			d.AnSetAdd(an)
+		}
+	}
+	d.Stage.anChained = chained[:0]
+	if d.Mask != nil {
+		d.Mask.Tick(tick)
+	}
 
 	d.dobs.Range(func(id int64, d *Dob) bool {
 		// we need this check to support racing Ans
@@ -229,24 +335,90 @@ func (d *Dob) Tick(tick int32) {
 
 // Paint
 // Puts textures and rectangles on the view. Runs all all embedded dobs.
+// Skips hidden subtrees, and dobs that are off the render target or transparent.
 func (d *Dob) Paint() {
-	dobsPainted++
-	dst := sdl.Rect{X: int32(d.Px - d.Scale*d.zoom*float32(d.D[0])/2), Y: int32(d.Py - d.Scale*d.zoom*float32(d.D[1])/2), W: int32(d.Scale * d.zoom * float32(d.D[0])), H: int32(d.Scale * d.zoom * float32(d.D[1]))}
-	if d.Texture != nil {
-		src := sdl.Rect{X: 0, Y: 0, W: d.D[0], H: d.D[1]}
-		d.Stage.view.Renderer.CopyEx(d.Texture.SDLTexture, &src, &dst, d.angle, nil, sdl.FLIP_NONE)
+	stats := &d.Stage.stats
+	if d.Hidden {
+		stats.Hidden++
+		return
+	}
+	if d.Cache != nil && !d.Cache.painting {
+		d.cachePaint()
+		return
+	}
+	if d.Mask != nil && (d.mask == nil || !d.mask.painting) && d.maskPaint() {
+		return
+	}
+	xf := &d.Stage.xf
+	d.dirty = d.paintStateCheck()
+	if d.dirty {
+		stats.Dirty++
+	}
+	if d.Clip {
+		if d.culled || !d.clipPush() {
+			// the subtree lies within the dob
+			stats.Culled++
+			return
+		}
+		defer d.clipPop()
+	}
+
+	if d.Painter != nil {
+		// painters draw outside the dob rect (eg. particles), so cannot cull by it
+		d.Painter.Paint(d)
+		stats.Painted++
+	} else if d.culled {
+		stats.Culled++
+	} else if d.Texture != nil {
+		src := &d.Stage.paintSrc
+		*src = sdl.Rect{X: 0, Y: 0, W: d.D[0], H: d.D[1]}
+		d.Texture.blendSet(d.Blend)
+		d.Stage.view.Renderer.CopyEx(d.Texture.SDLTexture, src, &d.dst, d.angle+xf.rot, nil, d.Flip)
+		stats.Painted++
 	} else if d.FillC.A > 0 {
+		d.Stage.view.Renderer.SetDrawBlendMode(d.Blend.sdl())
 		d.Stage.view.Renderer.SetDrawColor(d.FillC.R, d.FillC.G, d.FillC.B, d.FillC.A)
-		d.Stage.view.Renderer.FillRect(&dst)
+		d.Stage.view.Renderer.FillRect(&d.dst)
+		stats.Painted++
+	} else {
+		stats.Culled++
+	}
+	if g := d.Stage.debug; g != nil && g.Boxes && g.Visible && !d.culled {
+		g.boxAdd(d)
 	}
 
-	d.dobs.Range(func(id int64, d *Dob) bool {
+	d.paintQSort()
+	for _, b := range d.paintQ {
+		b.Paint()
+	}
+}
+
+// paintQSort loads the children into paintQ and stable sorts them by Z (and Py for YSort)
+func (d *Dob) paintQSort() {
+	d.paintQ = d.paintQ[:0]
+	d.dobs.Range(func(id int64, b *Dob) bool {
 		// we have to do this check to support racing Ans
-		if d != nil {
-			d.Paint()
+		if b != nil {
+			d.paintQ = append(d.paintQ, b)
 		}
 		return true
 	})
+
+	// insertion sort is stable, allocation free and linear for the usual (sorted) case
+	q := d.paintQ
+	for i := 1; i < len(q); i++ {
+		for j := i; j > 0 && d.paintsBefore(q[j], q[j-1]); j-- {
+			q[j], q[j-1] = q[j-1], q[j]
+		}
+	}
+}
+
+// paintsBefore reports whether child a must paint before child b
+func (d *Dob) paintsBefore(a, b *Dob) bool {
+	if a.Z != b.Z {
+		return a.Z < b.Z
+	}
+	return d.YSort && a.Py < b.Py
 }
 
 // TxtOut sugar to set text outline properties all at once
@@ -263,76 +435,108 @@ func (d *Dob) TxtFill(txt string, fillC sdl.Color, font *ttf.Font) {
 }
 
 // TxtFillOut sugar to set all text properties all at once and render
-func (d *Dob) TxtFillOut(txt string, fillC sdl.Color, font *ttf.Font, outW int, outC sdl.Color) {
+func (d *Dob) TxtFillOut(txt string, fillC sdl.Color, font *ttf.Font, outW int, outC sdl.Color) error {
 	d.FillC = fillC
 	d.TxtOutC = outC
 	d.TxtOutW = outW
 	d.txt = txt
 	d.txtFont = font
-	d.TxtRender()
+	return d.TxtRender()
 }
 
 // TxtRender renders text. Call after changes to text properties.
-func (d *Dob) TxtRender() (err error) {
+// Errors match ErrRender. The dob keeps its size and paints nothing on error.
+func (d *Dob) TxtRender() error {
 	if d.Texture != nil && d.Texture.SDLTexture != nil {
 		d.Texture.SDLTexture.Destroy()
 	}
 	d.Texture = &Texture{}
+	if d.txtFont == nil {
+		return renderErr("text", errors.New("no font"))
+	}
+	var surface *sdl.Surface
 	if d.TxtOutW > 0 {
 		// render text with outline
 		d.txtFont.SetOutline(d.TxtOutW)
-		outlineSurface, _ := d.txtFont.RenderUTF8Blended(d.txt, d.TxtOutC)
+		outlineSurface, err := d.txtFont.RenderUTF8Blended(d.txt, d.TxtOutC)
 		d.txtFont.SetOutline(0)
-		fillSurface, _ := d.txtFont.RenderUTF8Blended(d.txt, d.FillC)
+		if err != nil {
+			return renderErr("text outline", err)
+		}
+		defer outlineSurface.Free()
+		fillSurface, err := d.txtFont.RenderUTF8Blended(d.txt, d.FillC)
+		if err != nil {
+			return renderErr("text", err)
+		}
+		defer fillSurface.Free()
 		src := &sdl.Rect{X: 0, Y: 0, W: fillSurface.W, H: fillSurface.H}
 		dst := &sdl.Rect{X: int32(d.TxtOutW), Y: int32(d.TxtOutW), W: fillSurface.W, H: fillSurface.H}
 		// fillSurface.SetBlendMode(sdl.BLENDMODE_BLEND)
-		fillSurface.Blit(src, outlineSurface, dst)
-		d.Texture.SDLTexture, _ = d.Stage.view.Renderer.CreateTextureFromSurface(outlineSurface)
-		d.D[0] = outlineSurface.W
-		d.D[1] = outlineSurface.H
-		fillSurface.Free()
-		outlineSurface.Free()
+		if err := fillSurface.Blit(src, outlineSurface, dst); err != nil {
+			return renderErr("text outline", err)
+		}
+		surface = outlineSurface
 	} else {
 		// render text without outline
-		fillSurface, _ := d.txtFont.RenderUTF8Solid(d.txt, d.FillC)
-		d.Texture.SDLTexture, _ = d.Stage.view.Renderer.CreateTextureFromSurface(fillSurface)
-		d.D[0] = fillSurface.W
-		d.D[1] = fillSurface.H
-		fillSurface.Free()
+		fillSurface, err := d.txtFont.RenderUTF8Solid(d.txt, d.FillC)
+		if err != nil {
+			return renderErr("text", err)
+		}
+		defer fillSurface.Free()
+		surface = fillSurface
 	}
-	return
+	tex, err := d.Stage.view.Renderer.CreateTextureFromSurface(surface)
+	if err != nil {
+		return renderErr("text texture", err)
+	}
+	d.Texture.SDLTexture = tex
+	d.D[0] = surface.W
+	d.D[1] = surface.H
+	return nil
 }
 
 // Spawn yields a new dob with d as its ctx.
 // This implies a parent-child relationship, which only affects render order now.
 // In future versions, dobs might use their ctx dobs as a reference frame for
 // relative positioning, zooming, etc.
-func (d *Dob) Spawn(path string) (dob *Dob, err error) {
-	dobID++
-	dob = &Dob{
-		BaseAn: BaseAn{id: dobID},
-		Scale:  d.Scale,
-		Stage:  d.Stage,
-		angle:  d.angle,
-		zoom:   1,
-	}
+// An empty path spawns a color rectangle, like SpawnRect.
+// Returns the TextureLoad error, eg. ErrAssetNotFound, and no dob if path does not load.
+func (d *Dob) Spawn(path string) (*Dob, error) {
 	if path == "" {
-		// spawn a color rectangle
-		dob.D[0] = 2
-		dob.D[1] = 2
-		dob.FillC = sdl.Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
-	} else {
-		// spawn a texture
-		dob.Texture, _ = d.Stage.view.TextureLoad(path)
-		dob.D[0] = dob.Texture.W
-		dob.D[1] = dob.Texture.H
+		return d.SpawnRect(), nil
 	}
-	dob.BaseAn.dob = dob
+	texture, err := d.Stage.view.TextureLoad(path)
+	if err != nil {
+		return nil, err
+	}
+	dob := d.spawn()
+	dob.Texture = texture
+	dob.D[0] = texture.W
+	dob.D[1] = texture.H
+	return dob, nil
+}
+
+// SpawnRect yields a new white 2x2 color rectangle with d as its ctx. Unlike Spawn it cannot fail
+func (d *Dob) SpawnRect() *Dob {
+	dob := d.spawn()
+	dob.D[0] = 2
+	dob.D[1] = 2
+	dob.FillC = sdl.Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	return dob
+}
 
-	// add to the spawner
+// spawn yields a new dob without a look and adds it to d
+func (d *Dob) spawn() *Dob {
+	dobID++
+	dob := d.Stage.Pool.dobGet()
+	dob.id = dobID
+	dob.Scale = d.Scale
+	dob.Stage = d.Stage
+	dob.angle = d.angle
+	dob.zoom = 1
+	dob.BaseAn.dob = dob
 	d.DobAdd(dob)
-	return
+	return dob
 }
 
 // DobAdd adds b to d
@@ -345,6 +549,12 @@ func (d *Dob) DobAdd(b *Dob) {
 	}
 	d.dobs.Set(b.id, b)
 	b.ctx = d
+	if b.anchor != nil {
+		b.anchorApply()
+	}
+	if d.Layout != nil {
+		d.Layout.Layout(d)
+	}
 }
 
 // DobRm this orphans b unless client code holds a reference
@@ -353,15 +563,66 @@ func (d *Dob) DobRm(b *Dob) {
 	b.ctx = nil
 }
 
+// BringToFront paints d after its siblings with the same Z
+func (d *Dob) BringToFront() {
+	if d.ctx == nil {
+		return
+	}
+	d.ctx.dobs.Delete(d.id)
+	d.ctx.dobs.Set(d.id, d)
+}
+
+// SendToBack paints d before its siblings with the same Z
+func (d *Dob) SendToBack() {
+	if d.ctx == nil {
+		return
+	}
+	d.ctx.dobs.SetAt(0, d.id, d)
+}
+
+// InsertBefore adds b to d just before ref, so b paints before ref if they share a Z.
+// Adds b at the end if ref is nil or not a child of d.
+func (d *Dob) InsertBefore(b *Dob, ref *Dob) {
+	d.insertAt(b, ref, 0)
+}
+
+// InsertAfter adds b to d just after ref, so b paints after ref if they share a Z.
+// Adds b at the end if ref is nil or not a child of d.
+func (d *Dob) InsertAfter(b *Dob, ref *Dob) {
+	d.insertAt(b, ref, 1)
+}
+
+// insertAt adds b at the index of ref plus offset
+func (d *Dob) insertAt(b *Dob, ref *Dob, offset int) {
+	d.DobAdd(b)
+	if ref == nil || ref == b || ref.ctx != d {
+		return
+	}
+	d.dobs.Delete(b.id)
+	i := 0
+	d.dobs.Range(func(id int64, c *Dob) bool {
+		if c == ref {
+			return false
+		}
+		i++
+		return true
+	})
+	d.dobs.SetAt(i+offset, b.id, b)
+	if d.Layout != nil {
+		d.Layout.Layout(d)
+	}
+}
+
 // DobsClear removes all dobs. You probably want to call AnSetClear too.
 func (d *Dob) DobsClear() {
 	if d.dobs == nil {
 		return
 	}
-	d.dobs.Range(func(id int64, d *Dob) bool {
+	d.dobs.Range(func(id int64, b *Dob) bool {
 		// we need this check to support racing Ans
-		if d != nil {
-			d.Clear()
+		if b != nil {
+			b.Clear()
+			b.release()
 		}
 		return true
 	})
@@ -373,14 +634,41 @@ func (d *Dob) DobsClear() {
 func (d *Dob) Clear() {
 	d.DobsClear()
 	d.AnSetClear()
+	d.CacheOff()
+	d.MaskRm()
 	if d.txt != "" && d.Texture != nil && d.Texture.SDLTexture != nil {
 		d.Texture.SDLTexture.Destroy()
 	}
 }
 
+// release drops the stage references to d and hands it to the pool. Call d.Clear first.
+func (d *Dob) release() {
+	d.released = true
+	d.Stage.pointer.forget(d)
+	if g := d.Stage.debug; g != nil && g.selected == d {
+		g.selected = nil
+	}
+	d.Stage.Pool.dobPut(d)
+}
+
 // AnSetClear empties the AnSet. You probably want to call DobsClear too.
 func (d *Dob) AnSetClear() {
-	d.anSet = nil
+	if d.Stage.Pool == nil {
+		d.anSet = nil
+		return
+	}
+	for ID, an := range d.anSet {
+		delete(d.anSet, ID)
+		d.Stage.Pool.anPut(an, true)
+	}
+}
+
+// AnRm stops the An with id in the AnSet, eg. to retarget a tween. What it chained never starts.
+func (d *Dob) AnRm(id int64) {
+	if an, ok := d.anSet[id]; ok {
+		delete(d.anSet, id)
+		d.Stage.Pool.anPut(an, true)
+	}
 }
 
 // An is the dob animation interface
@@ -399,6 +687,12 @@ type BaseAn struct {
 	Duration  int64        // duration of the animation, after which it is over and removed from the anSet
 	Easer     Ease         // applies easing the the rate of the animation
 	StartTick int32        // first value of Tick passed to An.Tick // TODO set this before entry.
+	pooled    bool         // true while in a Pool
+}
+
+// base returns the BaseAn for pooling
+func (a *BaseAn) base() *BaseAn {
+	return a
 }
 
 // Dob returns the dob target
@@ -413,9 +707,8 @@ func (a *BaseAn) ID() int64 {
 }
 
 // AnSetAdd activates the animation.
-// TODO validate that the added an runs on the *current* tick. Otherwise a 1sec an followed by
-// another 1sec an will take 2sec + frameTimeMs to complete.
-// check how anSet and SliceMap.Range iterate
+// Added to an An, it runs from the tick that An completes, so a 1sec an followed by
+// another 1sec an takes 2sec. see Dob.Tick
 func (a *BaseAn) AnSetAdd(b An) An {
 	if a.anSet == nil {
 		a.anSet = make(map[int64]An, 0)
@@ -465,7 +758,10 @@ func (a *BaseAn) MoveTo(dstX float32, dstY float32, duration time.Duration, ease
 	if easer == nil {
 		easer = EaseNone
 	}
-	b := &MoveToAn{BaseAn: BaseAn{id: anID, dob: a.dob, anSet: nil, Duration: int64(duration), Easer: easer}, dstX: dstX, dstY: dstY}
+	b := anGet(a.dob.Stage.Pool, poolMoveTos)
+	b.BaseAn = BaseAn{id: anID, dob: a.dob, anSet: b.anSet, Duration: int64(duration), Easer: easer}
+	b.dstX = dstX
+	b.dstY = dstY
 	return a.AnSetAdd(b).(*MoveToAn)
 }
 
@@ -500,7 +796,9 @@ func (a *BaseAn) SpinTo(dst float64, duration time.Duration, easer Ease) *SpinAn
 	if easer == nil {
 		easer = EaseNone
 	}
-	b := &SpinAn{BaseAn: BaseAn{id: anID, dob: a.dob, anSet: nil, Duration: int64(duration), Easer: easer}, dst: dst}
+	b := anGet(a.dob.Stage.Pool, poolSpins)
+	b.BaseAn = BaseAn{id: anID, dob: a.dob, anSet: b.anSet, Duration: int64(duration), Easer: easer}
+	b.dst = dst
 	return a.AnSetAdd(b).(*SpinAn)
 }
 
@@ -535,7 +833,9 @@ func (a *BaseAn) ZoomTo(dst float32, duration time.Duration, easer Ease) *ZoomAn
 	if easer == nil {
 		easer = EaseNone
 	}
-	b := &ZoomAn{BaseAn: BaseAn{id: anID, dob: a.dob, anSet: nil, Duration: int64(duration), Easer: easer}, dst: dst}
+	b := anGet(a.dob.Stage.Pool, poolZooms)
+	b.BaseAn = BaseAn{id: anID, dob: a.dob, anSet: b.anSet, Duration: int64(duration), Easer: easer}
+	b.dst = dst
 	return a.AnSetAdd(b).(*ZoomAn)
 }
 
@@ -551,66 +851,54 @@ func (a *ZoomAn) Tick(tick int32) bool {
 	return pct == 1
 }
 
-// EmitAn spawns qty dobs emitter's position every interval for a duration
-// The lower bound of interval is the frame rate of the stage
-// EmitAn calls "then" for each emitted Dob. Use "then" to start Ans on the emitted dob
-type EmitAn struct {
+// ColorAn animates a color of a dob with easing
+type ColorAn struct {
 	BaseAn
-	Then         func(*Dob)
-	interval     time.Duration
-	lastEmitTick int32
-	qty          int
-	target       *Dob
-	template     *Dob
+	c    *sdl.Color // the color to animate. nil if the dob has none
+	dst  sdl.Color
+	from sdl.Color
+}
+
+// FillTo yields a ColorAn that tweens FillC, eg. of a color rect or Shape
+func (a *BaseAn) FillTo(dst sdl.Color, duration time.Duration, easer Ease) *ColorAn {
+	return a.colorTo(&a.dob.FillC, dst, duration, easer)
 }
 
-// Emit yields an EmitAn for BaseAn.Dob
-func (a *BaseAn) Emit(template *Dob, qty int, delayEach time.Duration, duration time.Duration, target *Dob, easer Ease, handler func(*Dob)) *EmitAn {
+// StrokeTo yields a ColorAn that tweens the StrokeC of a Shape dob
+func (a *BaseAn) StrokeTo(dst sdl.Color, duration time.Duration, easer Ease) *ColorAn {
+	var c *sdl.Color
+	if s := a.dob.Shape(); s != nil {
+		c = &s.StrokeC
+	}
+	return a.colorTo(c, dst, duration, easer)
+}
+
+// colorTo yields a ColorAn for c
+func (a *BaseAn) colorTo(c *sdl.Color, dst sdl.Color, duration time.Duration, easer Ease) *ColorAn {
 	anID++
 	if easer == nil {
 		easer = EaseNone
 	}
-	b := &EmitAn{
-		qty:      qty,
-		BaseAn:   BaseAn{id: anID, dob: a.dob, anSet: nil, Duration: int64(duration), Easer: easer},
-		template: template,
-		interval: delayEach,
-		target:   target,
-		Then:     handler,
-	}
-	return a.AnSetAdd(b).(*EmitAn)
-}
-
-func (a *EmitAn) Tick(tick int32) bool {
-	if a.StartTick == 0 || ((tick-a.lastEmitTick)*int32(a.dob.Stage.DurationPerTick) > int32(a.interval)) {
-		a.lastEmitTick = tick
-
-		for i := 0; i < a.qty; i++ {
-			c := a.template
-			b, _ := c.Spawn("")
-			b.FillC = c.FillC
-			b.D = c.D
-			b.Duration = c.Duration
-			b.Easer = c.Easer
-			b.Px = c.Px
-			b.Py = c.Py
-			b.Stage = c.Stage
-			b.Texture = c.Texture
-			b.zoom = c.zoom
-			b.StartTick = 0
-
-			if a.target != nil {
-				c.dobs.Delete(b.id)
-				a.target.dobs.Set(b.id, b)
-				b.ctx = a.target
-			}
-			a.Then(b)
-		}
+	b := anGet(a.dob.Stage.Pool, poolColors)
+	b.BaseAn = BaseAn{id: anID, dob: a.dob, anSet: b.anSet, Duration: int64(duration), Easer: easer}
+	b.c = c
+	b.dst = dst
+	return a.AnSetAdd(b).(*ColorAn)
+}
+
+func (a *ColorAn) Tick(tick int32) bool {
+	if a.c == nil {
+		return true
 	}
 	if a.StartTick == 0 {
 		a.StartTick = tick
+		a.from = *a.c
+	}
+	pct, eased := a.PC(tick)
+	if pct == 1 {
+		eased = 1 // land on dst, as channels truncate
 	}
-	pct, _ := a.PC(tick)
+	*a.c = colorLerp(a.from, a.dst, eased)
 	return pct == 1
 }
 
@@ -624,7 +912,9 @@ type ThenAn struct {
 // Then yields a ThenAn for BaseAn.Dob
 func (a *BaseAn) Then(fn func(*Dob)) *ThenAn {
 	anID++
-	b := &ThenAn{BaseAn: BaseAn{id: anID, dob: a.dob, anSet: nil, Duration: 0, Easer: nil}, then: fn}
+	b := anGet(a.dob.Stage.Pool, poolThens)
+	b.BaseAn = BaseAn{id: anID, dob: a.dob, anSet: b.anSet, Duration: 0, Easer: nil}
+	b.then = fn
 	return a.AnSetAdd(b).(*ThenAn)
 }
 
@@ -633,6 +923,27 @@ func (a *ThenAn) Tick(tick int32) bool {
 	return true
 }
 
+// EveryAn calls fn on every Tick until fn returns true.
+// Use it to drive custom animation (eg. from other packages) without a new An type.
+type EveryAn struct {
+	BaseAn
+	fn func(d *Dob, tick int32) bool
+}
+
+// Every yields an EveryAn for BaseAn.Dob
+func (a *BaseAn) Every(fn func(d *Dob, tick int32) bool) *EveryAn {
+	anID++
+	b := &EveryAn{BaseAn: BaseAn{id: anID, dob: a.dob, anSet: nil, Duration: 0, Easer: nil}, fn: fn}
+	return a.AnSetAdd(b).(*EveryAn)
+}
+
+func (a *EveryAn) Tick(tick int32) bool {
+	if a.StartTick == 0 {
+		a.StartTick = tick
+	}
+	return a.fn(a.dob, tick)
+}
+
 // PromiseAn calls launcherFn once on the first Tick.
 // launcherFn must complete in constant time to preserve the framerate.
 // it launches computation through new Ans or goroutines
@@ -648,7 +959,9 @@ type PromiseAn struct {
 // Promise yields a PromiseAn for BaseAn.Dob
 func (a *BaseAn) Promise(launcherFn func(dob *Dob, lock *atomic.Bool)) *PromiseAn {
 	anID++
-	b := &PromiseAn{BaseAn: BaseAn{id: anID, dob: a.dob, anSet: nil, Duration: 0, Easer: nil}, launcherFn: launcherFn}
+	b := anGet(a.dob.Stage.Pool, poolPromises)
+	b.BaseAn = BaseAn{id: anID, dob: a.dob, anSet: b.anSet, Duration: 0, Easer: nil}
+	b.launcherFn = launcherFn
 	return a.AnSetAdd(b).(*PromiseAn)
 }
 
@@ -670,7 +983,9 @@ type ResolveAn struct {
 // Resolve yields a ResolveAn for BaseAn.Dob
 func (a *BaseAn) Resolve(resolver *atomic.Bool) *ResolveAn {
 	anID++
-	b := &ResolveAn{BaseAn: BaseAn{id: anID, dob: a.dob, anSet: nil, Duration: 0, Easer: nil}, resolver: resolver}
+	b := anGet(a.dob.Stage.Pool, poolResolves)
+	b.BaseAn = BaseAn{id: anID, dob: a.dob, anSet: b.anSet, Duration: 0, Easer: nil}
+	b.resolver = resolver
 	return a.AnSetAdd(b).(*ResolveAn)
 }
 
@@ -688,12 +1003,17 @@ type ExitAn struct {
 // Exit yields an ExitAn for BaseAn.Dob
 func (a *BaseAn) Exit() *ExitAn {
 	anID++
-	b := &ExitAn{BaseAn: BaseAn{id: anID, dob: a.dob, anSet: nil, Duration: 0, Easer: nil}}
+	b := anGet(a.dob.Stage.Pool, poolExits)
+	b.BaseAn = BaseAn{id: anID, dob: a.dob, anSet: b.anSet, Duration: 0, Easer: nil}
 	return a.AnSetAdd(b).(*ExitAn)
 }
 
 func (a *ExitAn) Tick(tick int32) bool {
+	if a.dob.released {
+		return true
+	}
 	a.dob.ctx.DobRm(a.dob)
 	a.dob.Clear()
+	a.dob.release()
 	return true
 }
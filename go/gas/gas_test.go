@@ -0,0 +1,142 @@
+package gas
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// namesOf returns the names of the dobs in q, eg. "a b c"
+func namesOf(q []*Dob, names map[*Dob]string) string {
+	var b strings.Builder
+	for i, d := range q {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(names[d])
+	}
+	return b.String()
+}
+
+// childNames returns the names of the children of d in insertion order
+func childNames(d *Dob, names map[*Dob]string) string {
+	var q []*Dob
+	d.dobs.Range(func(id int64, b *Dob) bool {
+		q = append(q, b)
+		return true
+	})
+	return namesOf(q, names)
+}
+
+func TestInsert(t *testing.T) {
+	tests := []struct {
+		name    string
+		sibling bool // x starts as the last child of ctx, not in another ctx
+		insert  func(ctx *Dob, b *Dob, kids []*Dob)
+		want    string
+	}{
+		{"before the first", false, func(ctx, b *Dob, k []*Dob) { ctx.InsertBefore(b, k[0]) }, "x a b c"},
+		{"before the middle", false, func(ctx, b *Dob, k []*Dob) { ctx.InsertBefore(b, k[1]) }, "a x b c"},
+		{"after the middle", false, func(ctx, b *Dob, k []*Dob) { ctx.InsertAfter(b, k[1]) }, "a b x c"},
+		{"after the last", false, func(ctx, b *Dob, k []*Dob) { ctx.InsertAfter(b, k[2]) }, "a b c x"},
+		{"nil ref", false, func(ctx, b *Dob, k []*Dob) { ctx.InsertBefore(b, nil) }, "a b c x"},
+		{"ref of another ctx", false, func(ctx, b *Dob, k []*Dob) { ctx.InsertBefore(b, ctx.Stage.Root) }, "a b c x"},
+		{"ref is b", false, func(ctx, b *Dob, k []*Dob) { ctx.InsertBefore(b, b) }, "a b c x"},
+		{"move a sibling", true, func(ctx, b *Dob, k []*Dob) { ctx.InsertAfter(k[0], k[2]) }, "b c a x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stageTest()
+			ctx := s.Root.SpawnRect()
+			names := map[*Dob]string{}
+			var kids []*Dob
+			for _, n := range []string{"a", "b", "c"} {
+				d := ctx.SpawnRect()
+				names[d] = n
+				kids = append(kids, d)
+			}
+			other := s.Root.SpawnRect()
+			x := other.SpawnRect()
+			names[x] = "x"
+			if tt.sibling {
+				ctx.DobAdd(x)
+			}
+			tt.insert(ctx, x, kids)
+			if got := childNames(ctx, names); got != tt.want {
+				t.Errorf("children %q, want %q", got, tt.want)
+			}
+			if x.ctx != ctx {
+				t.Error("x is not a child of ctx")
+			}
+			if other.dobs.Len() != 0 {
+				t.Error("x is still a child of its old ctx")
+			}
+		})
+	}
+}
+
+func TestPaintOrder(t *testing.T) {
+	type kid struct {
+		name string
+		z    int
+		py   float32
+	}
+	tests := []struct {
+		name  string
+		ySort bool
+		kids  []kid
+		want  string
+	}{
+		{"insertion order", false, []kid{{"a", 0, 0}, {"b", 0, 0}, {"c", 0, 0}}, "a b c"},
+		{"z first", false, []kid{{"a", 1, 0}, {"b", -1, 0}, {"c", 0, 0}}, "b c a"},
+		{"ties keep insertion order", false, []kid{{"a", 1, 0}, {"b", 0, 0}, {"c", 1, 0}, {"d", 0, 0}}, "b d a c"},
+		{"no ysort without the flag", false, []kid{{"a", 0, 30}, {"b", 0, 10}, {"c", 0, 20}}, "a b c"},
+		{"ysort", true, []kid{{"a", 0, 30}, {"b", 0, 10}, {"c", 0, 20}}, "b c a"},
+		{"ysort under z", true, []kid{{"a", 0, 10}, {"b", 1, 0}, {"c", 0, 20}, {"d", -1, 50}}, "d a c b"},
+		{"ysort ties keep insertion order", true, []kid{{"a", 0, 10}, {"b", 0, 5}, {"c", 0, 10}, {"d", 0, 5}}, "b d a c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stageTest()
+			ctx := s.Root.SpawnRect()
+			ctx.YSort = tt.ySort
+			names := map[*Dob]string{}
+			for _, k := range tt.kids {
+				d := ctx.SpawnRect()
+				d.Z, d.Py = k.z, k.py
+				names[d] = k.name
+			}
+			ctx.paintQSort()
+			if got := namesOf(ctx.paintQ, names); got != tt.want {
+				t.Errorf("paint order %q, want %q", got, tt.want)
+			}
+			// sorting again is stable
+			ctx.paintQSort()
+			if got := namesOf(ctx.paintQ, names); got != tt.want {
+				t.Errorf("paint order after a second sort %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnRm(t *testing.T) {
+	s := stageTest()
+	d := s.Root.SpawnRect()
+	d.FillC = SDLC(0x000000ff)
+	then := 0
+	fill := d.FillTo(SDLC(0xffffffff), time.Second, nil)
+	fill.Then(func(*Dob) { then++ })
+	d.ZoomTo(2, time.Second, nil)
+	s.Tick(1)
+	d.AnRm(fill.ID())
+	d.AnRm(fill.ID()) // gone already
+	for tick := int32(2); tick <= 40; tick++ {
+		s.Tick(tick)
+	}
+	if d.FillC != SDLC(0x000000ff) || then != 0 {
+		t.Errorf("fill %v and Then ran %d times after AnRm, want black and 0", d.FillC, then)
+	}
+	if d.zoom != 2 || len(d.anSet) != 0 {
+		t.Errorf("zoom %v with %d Ans left, want 2 and none", d.zoom, len(d.anSet))
+	}
+}
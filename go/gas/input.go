@@ -0,0 +1,311 @@
+package gas
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Button names a key, mouse button or gamepad button in one space.
+// Keys use SDL scancodes (physical positions), so WASD works on any layout.
+type Button int32
+
+// Key returns the Button for an SDL scancode
+func Key(sc sdl.Scancode) Button {
+	return Button(sc)
+}
+
+// Pointer and text pseudo buttons follow the scancodes
+const (
+	MouseMove   Button = sdl.NUM_SCANCODES + iota // pointer motion. never down
+	MouseLeft                                     // also the first finger on touch screens
+	MouseMiddle                                   //
+	MouseRight                                    //
+	MouseX1                                       //
+	MouseX2                                       //
+	KeyText                                       // text input. see InputEvent.Text. never down
+	padBase
+)
+
+// Gamepad buttons. Input merges all connected gamepads.
+const (
+	PadA          = padBase + sdl.CONTROLLER_BUTTON_A
+	PadB          = padBase + sdl.CONTROLLER_BUTTON_B
+	PadX          = padBase + sdl.CONTROLLER_BUTTON_X
+	PadY          = padBase + sdl.CONTROLLER_BUTTON_Y
+	PadBack       = padBase + sdl.CONTROLLER_BUTTON_BACK
+	PadStart      = padBase + sdl.CONTROLLER_BUTTON_START
+	PadL          = padBase + sdl.CONTROLLER_BUTTON_LEFTSHOULDER
+	PadR          = padBase + sdl.CONTROLLER_BUTTON_RIGHTSHOULDER
+	PadUp         = padBase + sdl.CONTROLLER_BUTTON_DPAD_UP
+	PadDown       = padBase + sdl.CONTROLLER_BUTTON_DPAD_DOWN
+	PadLeft       = padBase + sdl.CONTROLLER_BUTTON_DPAD_LEFT
+	PadRight      = padBase + sdl.CONTROLLER_BUTTON_DPAD_RIGHT
+	PadStickUp    = padBase + sdl.CONTROLLER_BUTTON_MAX + iota // left stick pushed past the dead zone
+	PadStickDown                                               //
+	PadStickLeft                                               //
+	PadStickRight                                              //
+	buttonMax
+)
+
+// padDeadZone is how far the left stick must move to press a PadStick button
+const padDeadZone = 16000
+
+// touchMouseID marks mouse events that SDL synthesized from touches
+const touchMouseID = ^uint32(0)
+
+// InputEvent is a change to one Button
+type InputEvent struct {
+	Button Button
+	Down   bool    // pressed if true, released otherwise
+	Text   string  // typed text for KeyText
+	Tick   int32   // tick when the event applied
+	X      float32 // pointer position in view coordinates
+	Y      float32
+}
+
+// Input samples keyboard, mouse, touch and gamepad state at tick boundaries.
+// Events that arrive between ticks apply together at the start of the next tick,
+// so state reads during a tick are stable and replays are deterministic.
+type Input struct {
+	X         float32                    // pointer position in view coordinates
+	Y         float32                    //
+	actions   map[string][]Button        // action bindings
+	cancelled bool                       // the event in delivery stops reaching subscribers. see Cancel
+	down      [buttonMax]bool            // current state
+	pressed   [buttonMax]bool            // went down this tick
+	queue     []InputEvent               // events since the last tick
+	released  [buttonMax]bool            // went up this tick
+	subs      map[int]func(e InputEvent) // subscribers by id
+	subsQ     []int                      // subscriber ids in subscription order
+	subID     int
+	view      *View
+}
+
+// MakeInput returns an Input for events on v
+func MakeInput(v *View) *Input {
+	return &Input{
+		actions: make(map[string][]Button),
+		subs:    make(map[int]func(e InputEvent)),
+		view:    v,
+	}
+}
+
+// IsDown reports whether b is down
+func (in *Input) IsDown(b Button) bool {
+	return in.down[b]
+}
+
+// JustPressed reports whether b went down on this tick
+func (in *Input) JustPressed(b Button) bool {
+	return in.pressed[b]
+}
+
+// JustReleased reports whether b went up on this tick
+func (in *Input) JustReleased(b Button) bool {
+	return in.released[b]
+}
+
+// Bind adds buttons to the named action, eg. Bind("up", Key(sdl.SCANCODE_W), Key(sdl.SCANCODE_UP), PadUp)
+func (in *Input) Bind(action string, buttons ...Button) {
+	in.actions[action] = append(in.actions[action], buttons...)
+}
+
+// Unbind removes all buttons from the named action
+func (in *Input) Unbind(action string) {
+	delete(in.actions, action)
+}
+
+// Bound reports whether b triggers the named action
+func (in *Input) Bound(action string, b Button) bool {
+	for _, a := range in.actions[action] {
+		if a == b {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionDown reports whether any button of the action is down
+func (in *Input) ActionDown(action string) bool {
+	for _, b := range in.actions[action] {
+		if in.down[b] {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionJustPressed reports whether any button of the action went down on this tick
+func (in *Input) ActionJustPressed(action string) bool {
+	for _, b := range in.actions[action] {
+		if in.pressed[b] {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionJustReleased reports whether any button of the action went up on this
+// tick and none remain down
+func (in *Input) ActionJustReleased(action string) bool {
+	released := false
+	for _, b := range in.actions[action] {
+		if in.down[b] {
+			return false
+		}
+		released = released || in.released[b]
+	}
+	return released
+}
+
+// On subscribes fn to every InputEvent. fn runs at the tick boundary, before Ans tick.
+// Returns an id for Off.
+func (in *Input) On(fn func(e InputEvent)) int {
+	in.subID++
+	in.subs[in.subID] = fn
+	in.subsQ = append(in.subsQ, in.subID)
+	return in.subID
+}
+
+// Cancel stops the event in delivery from reaching later subscribers, eg. so a focused
+// dialog keeps keys from the game controls. Call it from an On subscriber.
+func (in *Input) Cancel() {
+	in.cancelled = true
+}
+
+// Off unsubscribes
+func (in *Input) Off(id int) {
+	delete(in.subs, id)
+	for i, sid := range in.subsQ {
+		if sid == id {
+			in.subsQ = append(in.subsQ[:i], in.subsQ[i+1:]...)
+			return
+		}
+	}
+}
+
+// pads are the open gamepads. SDL opens them for the process, so every view and scene shares them.
+var pads = map[sdl.JoystickID]*sdl.GameController{}
+
+// padEvent opens and closes gamepads as they connect and disconnect
+func padEvent(e *sdl.ControllerDeviceEvent) {
+	switch e.Type {
+	case sdl.CONTROLLERDEVICEADDED:
+		if pad := sdl.GameControllerOpen(int(e.Which)); pad != nil {
+			pads[pad.Joystick().InstanceID()] = pad
+		}
+	case sdl.CONTROLLERDEVICEREMOVED:
+		if pad, ok := pads[e.Which]; ok {
+			pad.Close()
+			delete(pads, e.Which)
+		}
+	}
+}
+
+// padsClose closes the open gamepads
+func padsClose() {
+	for id, pad := range pads {
+		pad.Close()
+		delete(pads, id)
+	}
+}
+
+// Handle queues an SDL event for the next tick. Returns false if the event
+// is not an input event. sdl.PollEvent reuses its event, so copy out now.
+func (in *Input) Handle(event sdl.Event) bool {
+	switch e := event.(type) {
+	case *sdl.KeyboardEvent:
+		if e.Repeat == 0 && e.Keysym.Scancode < sdl.NUM_SCANCODES {
+			in.enqueue(Key(e.Keysym.Scancode), e.State == sdl.PRESSED, in.X, in.Y)
+		}
+	case *sdl.TextInputEvent:
+		in.queue = append(in.queue, InputEvent{Button: KeyText, Text: e.GetText(), X: in.X, Y: in.Y})
+	case *sdl.MouseMotionEvent:
+		if e.Which != touchMouseID {
+			in.enqueue(MouseMove, false, float32(e.X), float32(e.Y))
+		}
+	case *sdl.MouseButtonEvent:
+		if e.Which != touchMouseID && e.Button <= sdl.BUTTON_X2 {
+			in.enqueue(MouseMove+Button(e.Button), e.State == sdl.PRESSED, float32(e.X), float32(e.Y))
+		}
+	case *sdl.TouchFingerEvent:
+		x, y := e.X*float32(in.view.W), e.Y*float32(in.view.H)
+		switch e.Type {
+		case sdl.FINGERDOWN:
+			in.enqueue(MouseLeft, true, x, y)
+		case sdl.FINGERUP:
+			in.enqueue(MouseLeft, false, x, y)
+		default:
+			in.enqueue(MouseMove, false, x, y)
+		}
+	case *sdl.ControllerDeviceEvent:
+		padEvent(e)
+	case *sdl.ControllerButtonEvent:
+		if int(e.Button) < sdl.CONTROLLER_BUTTON_MAX {
+			in.enqueue(padBase+Button(e.Button), e.State == sdl.PRESSED, in.X, in.Y)
+		}
+	case *sdl.ControllerAxisEvent:
+		switch e.Axis {
+		case sdl.CONTROLLER_AXIS_LEFTX:
+			in.stick(PadStickLeft, PadStickRight, e.Value)
+		case sdl.CONTROLLER_AXIS_LEFTY:
+			in.stick(PadStickUp, PadStickDown, e.Value)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// stick converts an axis into presses of the lo and hi PadStick buttons
+func (in *Input) stick(lo, hi Button, v int16) {
+	loDown, hiDown := v < -padDeadZone, v > padDeadZone
+	if loDown != in.queuedDown(lo) {
+		in.enqueue(lo, loDown, in.X, in.Y)
+	}
+	if hiDown != in.queuedDown(hi) {
+		in.enqueue(hi, hiDown, in.X, in.Y)
+	}
+}
+
+// queuedDown returns the state of b after the queued events apply
+func (in *Input) queuedDown(b Button) bool {
+	for i := len(in.queue) - 1; i >= 0; i-- {
+		if in.queue[i].Button == b {
+			return in.queue[i].Down
+		}
+	}
+	return in.down[b]
+}
+
+func (in *Input) enqueue(b Button, down bool, x, y float32) {
+	in.queue = append(in.queue, InputEvent{Button: b, Down: down, X: x, Y: y})
+}
+
+// Sample applies the queued events and notifies subscribers. Stage.Tick calls it first thing.
+func (in *Input) Sample(tick int32) {
+	in.pressed = [buttonMax]bool{}
+	in.released = [buttonMax]bool{}
+	for i := range in.queue {
+		e := &in.queue[i]
+		e.Tick = tick
+		in.X, in.Y = e.X, e.Y
+		if e.Button != MouseMove && e.Button != KeyText && e.Down != in.down[e.Button] {
+			in.down[e.Button] = e.Down
+			if e.Down {
+				in.pressed[e.Button] = true
+			} else {
+				in.released[e.Button] = true
+			}
+		}
+		in.cancelled = false
+		for _, id := range in.subsQ {
+			if in.cancelled {
+				break
+			}
+			if fn, ok := in.subs[id]; ok {
+				fn(*e)
+			}
+		}
+	}
+	in.queue = in.queue[:0]
+}
@@ -0,0 +1,202 @@
+package gas
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// keyEvent returns an SDL key event for sc
+func keyEvent(sc sdl.Scancode, down bool) *sdl.KeyboardEvent {
+	e := &sdl.KeyboardEvent{Keysym: sdl.Keysym{Scancode: sc}, State: sdl.RELEASED}
+	if down {
+		e.State = sdl.PRESSED
+	}
+	return e
+}
+
+func TestInputEdges(t *testing.T) {
+	w, up := sdl.Scancode(sdl.SCANCODE_W), sdl.Scancode(sdl.SCANCODE_UP)
+	type state struct {
+		b                       Button
+		down, pressed, released bool
+	}
+	tests := []struct {
+		name   string
+		events [][]sdl.Event // per tick
+		want   []state       // after the last tick
+	}{
+		{
+			name:   "press",
+			events: [][]sdl.Event{{keyEvent(w, true)}},
+			want:   []state{{Key(w), true, true, false}},
+		},
+		{
+			name:   "held",
+			events: [][]sdl.Event{{keyEvent(w, true)}, {}},
+			want:   []state{{Key(w), true, false, false}},
+		},
+		{
+			name:   "release",
+			events: [][]sdl.Event{{keyEvent(w, true)}, {keyEvent(w, false)}},
+			want:   []state{{Key(w), false, false, true}},
+		},
+		{
+			name:   "tap within a tick",
+			events: [][]sdl.Event{{keyEvent(w, true), keyEvent(w, false)}},
+			want:   []state{{Key(w), false, true, true}},
+		},
+		{
+			name: "repeats do not press again",
+			events: [][]sdl.Event{
+				{keyEvent(w, true)},
+				{&sdl.KeyboardEvent{Keysym: sdl.Keysym{Scancode: w}, State: sdl.PRESSED, Repeat: 1}},
+			},
+			want: []state{{Key(w), true, false, false}},
+		},
+		{
+			name:   "keys apart",
+			events: [][]sdl.Event{{keyEvent(w, true), keyEvent(up, true)}, {keyEvent(w, false)}},
+			want:   []state{{Key(w), false, false, true}, {Key(up), true, false, false}},
+		},
+		{
+			name:   "mouse",
+			events: [][]sdl.Event{{&sdl.MouseButtonEvent{Button: sdl.BUTTON_RIGHT, State: sdl.PRESSED}}},
+			want:   []state{{MouseRight, true, true, false}, {MouseMove, false, false, false}},
+		},
+		{
+			name:   "mouse events from touches are ignored",
+			events: [][]sdl.Event{{&sdl.MouseButtonEvent{Which: touchMouseID, Button: sdl.BUTTON_LEFT, State: sdl.PRESSED}}},
+			want:   []state{{MouseLeft, false, false, false}},
+		},
+		{
+			name:   "touch",
+			events: [][]sdl.Event{{&sdl.TouchFingerEvent{Type: sdl.FINGERDOWN}}},
+			want:   []state{{MouseLeft, true, true, false}},
+		},
+		{
+			name:   "pad button",
+			events: [][]sdl.Event{{&sdl.ControllerButtonEvent{Button: sdl.CONTROLLER_BUTTON_A, State: sdl.PRESSED}}},
+			want:   []state{{PadA, true, true, false}},
+		},
+		{
+			name:   "unknown pad removed",
+			events: [][]sdl.Event{{&sdl.ControllerDeviceEvent{Type: sdl.CONTROLLERDEVICEREMOVED, Which: 7}}},
+			want:   []state{{PadA, false, false, false}},
+		},
+		{
+			name:   "stick inside the dead zone",
+			events: [][]sdl.Event{{&sdl.ControllerAxisEvent{Axis: sdl.CONTROLLER_AXIS_LEFTX, Value: -padDeadZone}}},
+			want:   []state{{PadStickLeft, false, false, false}},
+		},
+		{
+			name:   "stick past the dead zone",
+			events: [][]sdl.Event{{&sdl.ControllerAxisEvent{Axis: sdl.CONTROLLER_AXIS_LEFTY, Value: 30000}}},
+			want:   []state{{PadStickDown, true, true, false}, {PadStickUp, false, false, false}},
+		},
+		{
+			name: "stick flicks from side to side",
+			events: [][]sdl.Event{
+				{&sdl.ControllerAxisEvent{Axis: sdl.CONTROLLER_AXIS_LEFTX, Value: -30000}},
+				{
+					&sdl.ControllerAxisEvent{Axis: sdl.CONTROLLER_AXIS_LEFTX, Value: -20000},
+					&sdl.ControllerAxisEvent{Axis: sdl.CONTROLLER_AXIS_LEFTX, Value: 30000},
+				},
+			},
+			want: []state{{PadStickLeft, false, false, true}, {PadStickRight, true, true, false}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := MakeInput(&View{W: 800, H: 600})
+			for i, events := range tt.events {
+				for _, e := range events {
+					if !in.Handle(e) {
+						t.Fatalf("Handle(%T) = false", e)
+					}
+				}
+				in.Sample(int32(i + 1))
+			}
+			for _, w := range tt.want {
+				if in.IsDown(w.b) != w.down || in.JustPressed(w.b) != w.pressed || in.JustReleased(w.b) != w.released {
+					t.Errorf("button %d down, pressed, released = %v %v %v, want %v %v %v", w.b,
+						in.IsDown(w.b), in.JustPressed(w.b), in.JustReleased(w.b), w.down, w.pressed, w.released)
+				}
+			}
+		})
+	}
+}
+
+func TestInputActions(t *testing.T) {
+	in := MakeInput(&View{W: 800, H: 600})
+	in.Bind("up", Key(sdl.SCANCODE_W), Key(sdl.SCANCODE_UP))
+	in.Bind("up", PadUp)
+	tick := int32(0)
+	sample := func(events ...sdl.Event) {
+		for _, e := range events {
+			in.Handle(e)
+		}
+		tick++
+		in.Sample(tick)
+	}
+	check := func(step string, down, pressed, released bool) {
+		t.Helper()
+		if in.ActionDown("up") != down || in.ActionJustPressed("up") != pressed || in.ActionJustReleased("up") != released {
+			t.Errorf("%s: down, pressed, released = %v %v %v, want %v %v %v", step,
+				in.ActionDown("up"), in.ActionJustPressed("up"), in.ActionJustReleased("up"), down, pressed, released)
+		}
+	}
+
+	for _, b := range []Button{Key(sdl.SCANCODE_W), Key(sdl.SCANCODE_UP), PadUp} {
+		if !in.Bound("up", b) {
+			t.Errorf("button %d not bound to up", b)
+		}
+	}
+	if in.Bound("up", Key(sdl.SCANCODE_S)) || in.Bound("down", Key(sdl.SCANCODE_W)) {
+		t.Error("bound where not bound")
+	}
+	sample(keyEvent(sdl.SCANCODE_W, true))
+	check("press w", true, true, false)
+	sample(keyEvent(sdl.SCANCODE_UP, true))
+	check("press up too", true, true, false)
+	sample(keyEvent(sdl.SCANCODE_W, false))
+	check("release w with up down", true, false, false)
+	sample(keyEvent(sdl.SCANCODE_UP, false))
+	check("release up", false, false, true)
+	sample()
+	check("idle", false, false, false)
+
+	in.Unbind("up")
+	sample(keyEvent(sdl.SCANCODE_W, true))
+	check("unbound", false, false, false)
+}
+
+func TestInputSubscribers(t *testing.T) {
+	in := MakeInput(&View{W: 800, H: 600})
+	var got []string
+	first := in.On(func(e InputEvent) { got = append(got, "first") })
+	in.On(func(e InputEvent) {
+		got = append(got, "modal")
+		if e.Button == Key(sdl.SCANCODE_ESCAPE) {
+			in.Cancel()
+		}
+	})
+	in.On(func(e InputEvent) { got = append(got, "game") })
+
+	in.Handle(keyEvent(sdl.SCANCODE_ESCAPE, true))
+	in.Handle(keyEvent(sdl.SCANCODE_F3, true))
+	in.Sample(1)
+	want := "first modal first modal game"
+	if s := strings.Join(got, " "); s != want {
+		t.Errorf("delivery %q, want %q", s, want)
+	}
+
+	got = got[:0]
+	in.Off(first)
+	in.Handle(keyEvent(sdl.SCANCODE_F3, false))
+	in.Sample(2)
+	if s := strings.Join(got, " "); s != "modal game" {
+		t.Errorf("delivery after Off %q, want %q", s, "modal game")
+	}
+}
@@ -0,0 +1,428 @@
+package gas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Inspector serves a running Director over HTTP on localhost, for tools and automated playtests.
+// All paths act on the top scene. Reads and commands run on the game loop between frames.
+//
+//	GET  /tree                  the dob tree as JSON, with the running Ans of each dob
+//	GET  /dobs/{id}             one dob and its subtree
+//	POST /dobs/{id}             sets properties from JSON, eg. {"x": 10, "hidden": true, "fill": "#ff0000ff"}
+//	GET  /stats                 Stats as JSON
+//	GET  /stats/stream?ms=500   Stats as server-sent events
+//	POST /pause, /resume        pauses or resumes the stage. see Stage.Paused
+//	POST /step?n=1              runs n ticks while paused
+//	GET  /screenshot            the last frame as a PNG
+//
+// So web pages in the player's browser cannot drive or read the game, the inspector refuses
+// requests with an Origin header or a Host other than a loopback name, and POSTs that are not JSON.
+type Inspector struct {
+	closed   sync.Once
+	cmds     chan func() // run by the director between frames
+	director *Director
+	done     chan struct{}
+	listener net.Listener
+	port     string // the port listened on, for checking Host headers
+	server   *http.Server
+}
+
+// inspectTimeout is how long a request waits for the game loop
+const inspectTimeout = 5 * time.Second
+
+// Inspect serves the director at addr, which must be on localhost, eg. "localhost:6061".
+// Close the Inspector to stop.
+func (d *Director) Inspect(addr string) (*Inspector, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("inspector must listen on localhost, not %s", host)
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	in := &Inspector{cmds: make(chan func(), 16), director: d, done: make(chan struct{}), listener: l, port: port}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tree", in.tree)
+	mux.HandleFunc("/dobs/", in.dob)
+	mux.HandleFunc("/stats", in.stats)
+	mux.HandleFunc("/stats/stream", in.statsStream)
+	mux.HandleFunc("/pause", in.pause)
+	mux.HandleFunc("/resume", in.pause)
+	mux.HandleFunc("/step", in.step)
+	mux.HandleFunc("/screenshot", in.screenshot)
+	in.server = &http.Server{Handler: in.guard(mux)}
+	d.inspector = in
+	go in.server.Serve(l)
+	return in, nil
+}
+
+// Addr returns the address the inspector listens on
+func (in *Inspector) Addr() string {
+	return in.listener.Addr().String()
+}
+
+// Close stops serving. Later calls do nothing
+func (in *Inspector) Close() (err error) {
+	in.closed.Do(func() {
+		close(in.done)
+		err = in.server.Close()
+	})
+	return err
+}
+
+// guard refuses cross-origin and DNS rebinding requests before they reach h
+func (in *Inspector) guard(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "" {
+			http.Error(w, "cross-origin requests refused", http.StatusForbidden)
+			return
+		}
+		host, port, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host, port = r.Host, ""
+		}
+		ip := net.ParseIP(strings.Trim(host, "[]"))
+		if (host != "localhost" && (ip == nil || !ip.IsLoopback())) || (port != "" && port != in.port) {
+			http.Error(w, "host must be localhost", http.StatusForbidden)
+			return
+		}
+		if r.Method == http.MethodPost {
+			if mt := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]); mt != "application/json" {
+				http.Error(w, "POST needs Content-Type: application/json", http.StatusUnsupportedMediaType)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// drain runs the pending commands. Director.Frame calls it after painting, before Present.
+func (in *Inspector) drain() {
+	for {
+		select {
+		case fn := <-in.cmds:
+			fn()
+		default:
+			return
+		}
+	}
+}
+
+// run runs fn on the game loop and waits for it
+func (in *Inspector) run(fn func(s *Stage)) error {
+	ran := make(chan struct{})
+	cmd := func() {
+		defer close(ran)
+		if s := in.director.Top(); s != nil {
+			fn(s)
+		}
+	}
+	timeout := time.After(inspectTimeout)
+	select {
+	case in.cmds <- cmd:
+	case <-in.done:
+		return errors.New("inspector closed")
+	case <-timeout:
+		return errors.New("game loop busy")
+	}
+	select {
+	case <-ran:
+		return nil
+	case <-in.done:
+		return errors.New("inspector closed")
+	case <-timeout:
+		return errors.New("game loop busy")
+	}
+}
+
+// dobJSON is a dob as the inspector serves it
+type dobJSON struct {
+	ID      int64     `json:"id"`
+	Label   string    `json:"label"`
+	X       float32   `json:"x"`
+	Y       float32   `json:"y"`
+	W       int32     `json:"w"`
+	H       int32     `json:"h"`
+	Z       int       `json:"z"`
+	Angle   float64   `json:"angle"`
+	Scale   float32   `json:"scale"`
+	Zoom    float32   `json:"zoom"`
+	Fill    string    `json:"fill"`
+	Hidden  bool      `json:"hidden"`
+	Texture string    `json:"texture,omitempty"`
+	Text    string    `json:"text,omitempty"`
+	Ans     []anJSON  `json:"ans,omitempty"`
+	Dobs    []dobJSON `json:"dobs,omitempty"`
+}
+
+// anJSON is a running An as the inspector serves it
+type anJSON struct {
+	ID       int64    `json:"id"`
+	Type     string   `json:"type"`
+	Duration string   `json:"duration,omitempty"`
+	Then     []anJSON `json:"then,omitempty"` // chained ans
+}
+
+// dobJSONOf converts d and its subtree
+func dobJSONOf(d *Dob) dobJSON {
+	j := dobJSON{
+		ID: d.id, Label: debugLabel(d),
+		X: d.Px, Y: d.Py, W: d.D[0], H: d.D[1], Z: d.Z,
+		Angle: d.angle, Scale: d.Scale, Zoom: d.zoom,
+		Fill:   fmt.Sprintf("#%02x%02x%02x%02x", d.FillC.R, d.FillC.G, d.FillC.B, d.FillC.A),
+		Hidden: d.Hidden, Text: d.txt,
+	}
+	if d.Texture != nil {
+		j.Texture = d.Texture.path
+	}
+	for _, an := range d.anSet {
+		j.Ans = append(j.Ans, anJSONOf(an))
+	}
+	d.dobs.Range(func(id int64, b *Dob) bool {
+		if b != nil {
+			j.Dobs = append(j.Dobs, dobJSONOf(b))
+		}
+		return true
+	})
+	return j
+}
+
+// anJSONOf converts an and its chain
+func anJSONOf(an An) anJSON {
+	j := anJSON{ID: an.ID(), Type: strings.TrimPrefix(fmt.Sprintf("%T", an), "*gas.")}
+	if b, ok := an.(interface{ base() *BaseAn }); ok && b.base().Duration > 0 {
+		j.Duration = time.Duration(b.base().Duration).String()
+	}
+	for _, next := range an.AnSet() {
+		j.Then = append(j.Then, anJSONOf(next))
+	}
+	return j
+}
+
+// dobFind returns the dob with id in the stage or nil
+func dobFind(s *Stage, id int64) (found *Dob) {
+	var find func(d *Dob)
+	find = func(d *Dob) {
+		if d.id == id {
+			found = d
+			return
+		}
+		d.dobs.Range(func(_ int64, b *Dob) bool {
+			if b != nil {
+				find(b)
+			}
+			return found == nil
+		})
+	}
+	find(s.Root)
+	for _, l := range s.layers {
+		if found == nil {
+			find(&l.Dob)
+		}
+	}
+	return found
+}
+
+// tree serves the root and layers
+func (in *Inspector) tree(w http.ResponseWriter, r *http.Request) {
+	var tree []dobJSON
+	err := in.run(func(s *Stage) {
+		tree = append(tree, dobJSONOf(s.Root))
+		for _, l := range s.layers {
+			tree = append(tree, dobJSONOf(&l.Dob))
+		}
+	})
+	inspectReply(w, tree, err)
+}
+
+// dobProps are the properties POST /dobs/{id} sets. nil fields stay as they are.
+type dobProps struct {
+	Angle  *float64 `json:"angle"`
+	Fill   *string  `json:"fill"`
+	Hidden *bool    `json:"hidden"`
+	Scale  *float32 `json:"scale"`
+	X      *float32 `json:"x"`
+	Y      *float32 `json:"y"`
+	Z      *int     `json:"z"`
+	Zoom   *float32 `json:"zoom"`
+}
+
+// dob serves or sets one dob
+func (in *Inspector) dob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/dobs/"), 10, 64)
+	if err != nil {
+		http.Error(w, "bad dob id", http.StatusBadRequest)
+		return
+	}
+	var props dobProps
+	var fill uint64
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&props); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if props.Fill != nil {
+			if fill, err = strconv.ParseUint(strings.TrimPrefix(*props.Fill, "#"), 16, 32); err != nil {
+				http.Error(w, "fill must be #rrggbbaa", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	var j *dobJSON
+	err = in.run(func(s *Stage) {
+		d := dobFind(s, id)
+		if d == nil {
+			return
+		}
+		if props.Angle != nil {
+			d.angle = *props.Angle
+		}
+		if props.Fill != nil {
+			d.FillC = SDLC(uint32(fill))
+		}
+		if props.Scale != nil {
+			d.Scale = *props.Scale
+		}
+		if props.Hidden != nil {
+			d.Hidden = *props.Hidden
+		}
+		if props.X != nil {
+			d.Px = *props.X
+		}
+		if props.Y != nil {
+			d.Py = *props.Y
+		}
+		if props.Z != nil {
+			d.Z = *props.Z
+		}
+		if props.Zoom != nil {
+			d.zoom = *props.Zoom
+		}
+		dj := dobJSONOf(d)
+		j = &dj
+	})
+	if err == nil && j == nil {
+		http.Error(w, "no such dob", http.StatusNotFound)
+		return
+	}
+	inspectReply(w, j, err)
+}
+
+// statsJSON is Stats with the pause state
+type statsJSON struct {
+	Stats
+	Paused bool
+}
+
+// stats serves the stats of the last frame
+func (in *Inspector) stats(w http.ResponseWriter, r *http.Request) {
+	var stats statsJSON
+	err := in.run(func(s *Stage) {
+		stats = statsJSON{Stats: s.Stats(), Paused: s.Paused}
+	})
+	inspectReply(w, stats, err)
+}
+
+// statsStream sends the stats every ms milliseconds until the client goes
+func (in *Inspector) statsStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	ms, _ := strconv.Atoi(r.URL.Query().Get("ms"))
+	if ms <= 0 {
+		ms = 500
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	ticker := time.NewTicker(time.Duration(ms) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		var stats statsJSON
+		if err := in.run(func(s *Stage) { stats = statsJSON{Stats: s.Stats(), Paused: s.Paused} }); err != nil {
+			return
+		}
+		b, _ := json.Marshal(stats)
+		fmt.Fprintf(w, "data: %s\n\n", b)
+		flusher.Flush()
+		select {
+		case <-ticker.C:
+		case <-r.Context().Done():
+			return
+		case <-in.done:
+			return
+		}
+	}
+}
+
+// pause serves /pause and /resume
+func (in *Inspector) pause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", http.StatusMethodNotAllowed)
+		return
+	}
+	paused := r.URL.Path == "/pause"
+	err := in.run(func(s *Stage) { s.Paused = paused })
+	inspectReply(w, map[string]bool{"paused": paused}, err)
+}
+
+// step runs ticks while paused
+func (in *Inspector) step(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", http.StatusMethodNotAllowed)
+		return
+	}
+	n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+	if n <= 0 {
+		n = 1
+	}
+	err := in.run(func(s *Stage) { s.Step(n) })
+	inspectReply(w, map[string]int{"steps": n}, err)
+}
+
+// screenshot serves the frame just painted
+func (in *Inspector) screenshot(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	var encErr error
+	err := in.run(func(s *Stage) {
+		encErr = png.Encode(&buf, s.view.capture(nil))
+	})
+	if err == nil {
+		err = encErr
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes())
+}
+
+// inspectReply writes v as JSON, or err
+func inspectReply(w http.ResponseWriter, v any, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
@@ -0,0 +1,117 @@
+package gas
+
+import "math"
+
+// Layer is a named root dob for one slice of the stage (background, terrain,
+// actors, HUD, overlay...). Spawn into a layer like any other dob.
+// Layers paint in Z order. Root paints between the negative and non-negative layers.
+// Root never pauses, so spawn what should freeze with the game world into a layer.
+type Layer struct {
+	Dob
+	Name     string  // key for Stage.Layer
+	Parallax float32 // how far the layer tracks the camera. 1 moves with the world, 0 sticks to the screen
+	Paused   bool    // skips Tick for the whole layer, freezing its Ans
+	lag      int32   // ticks spent paused, taken off the stage tick so Ans resume where they paused
+}
+
+// LayerAdd adds a named layer to the stage
+func (s *Stage) LayerAdd(name string, z int, parallax float32) *Layer {
+	dobID++
+	l := &Layer{Name: name, Parallax: parallax}
+	l.Z = z
+	l.id = dobID
+	l.dob = &l.Dob
+	l.D[0] = s.view.W
+	l.D[1] = s.view.H
+	l.Px = float32(s.view.W) / 2
+	l.Py = float32(s.view.H) / 2
+	l.Scale = 1
+	l.Stage = s
+	l.zoom = 1
+	s.layers = append(s.layers, l)
+	return l
+}
+
+// Layer returns the named layer or nil
+func (s *Stage) Layer(name string) *Layer {
+	for _, l := range s.layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// LayerRm removes the named layer and clears its dobs
+func (s *Stage) LayerRm(name string) {
+	for i, l := range s.layers {
+		if l.Name == name {
+			s.layers = append(s.layers[:i], s.layers[i+1:]...)
+			l.Clear()
+			return
+		}
+	}
+}
+
+// layersTick ticks the unpaused layers
+func (s *Stage) layersTick(tick int32) {
+	for _, l := range s.layers {
+		if l.Paused {
+			l.lag++
+		} else {
+			l.Tick(tick - l.lag)
+		}
+	}
+}
+
+// tickOf returns the tick the Ans of d run on, that of its layer or of the stage
+func (s *Stage) tickOf(d *Dob) int32 {
+	for d.ctx != nil {
+		d = d.ctx
+	}
+	for _, l := range s.layers {
+		if d == &l.Dob {
+			return s.tick - l.lag
+		}
+	}
+	return s.tick
+}
+
+// layersPaint paints Root and the visible layers in Z order through base
+func (s *Stage) layersPaint(base xf) {
+	// insertion sort is stable, allocation free and quick for a handful of layers
+	for i := 1; i < len(s.layers); i++ {
+		for j := i; j > 0 && s.layers[j].Z < s.layers[j-1].Z; j-- {
+			s.layers[j], s.layers[j-1] = s.layers[j-1], s.layers[j]
+		}
+	}
+
+	rootPainted := false
+	for _, l := range s.layers {
+		if !rootPainted && l.Z >= 0 {
+			s.xf = base
+			s.Root.Paint()
+			rootPainted = true
+		}
+		s.xf = base.parallax(l.Parallax)
+		l.Paint()
+	}
+	if !rootPainted {
+		s.xf = base
+		s.Root.Paint()
+	}
+}
+
+// parallax blends x toward the identity transform by p. 1 leaves x as is.
+func (x xf) parallax(p float32) xf {
+	if p == 1 {
+		return x
+	}
+	x.camX = x.cx + (x.camX-x.cx)*p
+	x.camY = x.cy + (x.camY-x.cy)*p
+	x.zoom = 1 + (x.zoom-1)*p
+	x.rot *= float64(p)
+	sin, cos := math.Sincos(x.rot * math.Pi / 180)
+	x.sin, x.cos = float32(sin), float32(cos)
+	return x
+}
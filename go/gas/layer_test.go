@@ -0,0 +1,40 @@
+package gas
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLayerPause(t *testing.T) {
+	s := stageTest()
+	world, hud := s.LayerAdd("world", 0, 1), s.LayerAdd("hud", 2, 0)
+	moving := func(ctx *Dob) *Dob {
+		d := ctx.SpawnRect()
+		d.Move(0, 0)
+		d.MoveTo(300, 0, time.Second, nil)
+		return d
+	}
+	near := func(x, want float32) bool { return x > want-.01 && x < want+.01 }
+	r, w, h := moving(s.Root), moving(&world.Dob), moving(&hud.Dob)
+	s.Tick(1)
+	world.Paused = true
+	for tick := int32(2); tick <= 16; tick++ {
+		s.Tick(tick)
+	}
+	if w.Px != 0 {
+		t.Errorf("paused world dob moved to %v", w.Px)
+	}
+	if !near(r.Px, 150) || !near(h.Px, 150) {
+		t.Errorf("root and hud dobs at %v and %v, want 150", r.Px, h.Px)
+	}
+
+	// resuming picks up where the layer left off
+	world.Paused = false
+	s.Tick(17)
+	if !near(w.Px, 10) || s.tickOf(w) != 2 || s.tickOf(h) != 17 {
+		t.Errorf("resumed world dob at %v on tick %d, want 10 on tick 2", w.Px, s.tickOf(w))
+	}
+	if s.Layer("world") != world || s.Layer("nope") != nil {
+		t.Error("Layer does not find layers by name")
+	}
+}
@@ -0,0 +1,327 @@
+package gas
+
+// Anchor pins a dob to a point of the rect of its ctx, so the dob keeps its place
+// when the ctx resizes (eg. Root when the view resizes).
+// X and Y pick the point as fractions of the ctx rect (0 left or top, .5 center, 1 right or bottom).
+// The same point of the dob lands there, offset by DX, DY. So AnchorTopRight.Off(-16, 16)
+// keeps the top right corner of the dob 16px in from the top right corner of the ctx.
+// W and H, if set, size the dob to fractions of the ctx rect. Set one to keep the aspect ratio.
+// On Root and layers, the rect is the safe area of the stage unless Bleed. See Stage.SafeSet.
+type Anchor struct {
+	X     float32
+	Y     float32
+	DX    float32
+	DY    float32
+	W     float32
+	H     float32
+	Bleed bool // ignores the safe area, eg. for backgrounds
+}
+
+// Anchor presets for the corners, edge midpoints and center of the ctx. Adjust copies with Off and Size.
+var (
+	AnchorTopLeft     = Anchor{X: 0, Y: 0}
+	AnchorTop         = Anchor{X: .5, Y: 0}
+	AnchorTopRight    = Anchor{X: 1, Y: 0}
+	AnchorLeft        = Anchor{X: 0, Y: .5}
+	AnchorCenter      = Anchor{X: .5, Y: .5}
+	AnchorRight       = Anchor{X: 1, Y: .5}
+	AnchorBottomLeft  = Anchor{X: 0, Y: 1}
+	AnchorBottom      = Anchor{X: .5, Y: 1}
+	AnchorBottomRight = Anchor{X: 1, Y: 1}
+)
+
+// Off returns a copy of the anchor offset by dx, dy px
+func (a Anchor) Off(dx, dy float32) Anchor {
+	a.DX, a.DY = dx, dy
+	return a
+}
+
+// Bleeds returns a copy of the anchor that ignores the safe area of the stage
+func (a Anchor) Bleeds() Anchor {
+	a.Bleed = true
+	return a
+}
+
+// Insets are margins in px in from the edges of a rect
+type Insets struct {
+	Top    float32
+	Right  float32
+	Bottom float32
+	Left   float32
+}
+
+// SafeSet keeps anchored dobs in from the edges of the stage by in, eg. clear of TV overscan,
+// rounded corners or a notch. Anchors on Root and layers pin to the inset rect unless they Bleed.
+// Reapplies the anchors now and keeps the insets when the stage resizes.
+func (s *Stage) SafeSet(in Insets) {
+	s.safe = in
+	s.Root.Relayout()
+	for _, l := range s.layers {
+		l.Relayout()
+	}
+}
+
+// Safe returns the safe area insets. See SafeSet
+func (s *Stage) Safe() Insets {
+	return s.safe
+}
+
+// spans reports whether d covers the stage, ie. d is Root or a layer
+func (s *Stage) spans(d *Dob) bool {
+	if d == s.Root {
+		return true
+	}
+	for _, l := range s.layers {
+		if d == &l.Dob {
+			return true
+		}
+	}
+	return false
+}
+
+// Size returns a copy of the anchor that sizes the dob to w, h fractions of the ctx
+func (a Anchor) Size(w, h float32) Anchor {
+	a.W, a.H = w, h
+	return a
+}
+
+// Layout positions the children of a dob, eg. in a stack or grid.
+// Layouts run on Relayout, when a child is added and when the stage resizes.
+type Layout interface {
+	Layout(d *Dob)
+}
+
+// Anchor pins the dob to its ctx and applies the anchor now. See Anchor.
+func (d *Dob) Anchor(a Anchor) {
+	d.anchor = &a
+	d.Relayout()
+}
+
+// AnchorRm stops anchoring the dob. It stays where it is.
+func (d *Dob) AnchorRm() {
+	d.anchor = nil
+}
+
+// Relayout reapplies the anchors and layouts of d and its subtree.
+// Call it after changing the size or position of a container or its children.
+func (d *Dob) Relayout() {
+	if d.Layout != nil {
+		d.Layout.Layout(d) // sizes d to fit the children for the anchor
+	}
+	if d.anchor != nil && d.ctx != nil {
+		d.anchorApply()
+		if d.Layout != nil {
+			d.Layout.Layout(d)
+		}
+	}
+	d.dobs.Range(func(id int64, b *Dob) bool {
+		// we need this check to support racing Ans
+		if b != nil {
+			b.Relayout()
+		}
+		return true
+	})
+}
+
+// anchorApply sizes and positions d in the rect of its ctx, or in the safe area for Root and layers
+func (d *Dob) anchorApply() {
+	a := d.anchor
+	cx, cy := d.ctx.Px, d.ctx.Py
+	cw, ch := d.ctx.size()
+	if s := d.Stage; !a.Bleed && s != nil && s.spans(d.ctx) {
+		in := s.safe
+		cx += (in.Left - in.Right) / 2
+		cy += (in.Top - in.Bottom) / 2
+		cw -= in.Left + in.Right
+		ch -= in.Top + in.Bottom
+	}
+	if a.W > 0 || a.H > 0 {
+		d.sizeTo(a.W*cw, a.H*ch)
+	}
+	w, h := d.size()
+	d.Px = cx - cw/2 + a.X*cw + (.5-a.X)*w + a.DX
+	d.Py = cy - ch/2 + a.Y*ch + (.5-a.Y)*h + a.DY
+}
+
+// size returns the unzoomed size of d on the stage
+func (d *Dob) size() (float32, float32) {
+	return float32(d.D[0]) * d.Scale, float32(d.D[1]) * d.Scale
+}
+
+// sizeTo fits d to w x h on the stage. A zero w or h follows the other to keep the aspect ratio.
+// Resizes color rects. Scales everything else to fit within w x h, since D also crops the texture.
+func (d *Dob) sizeTo(w, h float32) {
+	if d.D[0] == 0 || d.D[1] == 0 {
+		return
+	}
+	aspect := float32(d.D[0]) / float32(d.D[1])
+	if w == 0 {
+		w = h * aspect
+	} else if h == 0 {
+		h = w / aspect
+	}
+	if d.Texture == nil && d.Painter == nil {
+		if d.Scale != 0 {
+			d.D[0], d.D[1] = int32(w/d.Scale), int32(h/d.Scale)
+		}
+		return
+	}
+	sx, sy := w/float32(d.D[0]), h/float32(d.D[1])
+	if sx < sy {
+		d.Scale = sx
+	} else {
+		d.Scale = sy
+	}
+}
+
+// HStack lays out the children of a dob left to right, in insertion order, centered
+// on the dob. Sizes color rect containers to fit. Skips hidden and empty children.
+type HStack struct {
+	Align float32 // vertical alignment of children. 0 top, .5 middle, 1 bottom
+	Gap   float32 // space between children
+	Pad   float32 // space around the children, for sizing the container
+}
+
+func (l HStack) Layout(d *Dob) {
+	stack(d, true, l.Align, l.Gap, l.Pad)
+}
+
+// VStack lays out the children of a dob top to bottom. See HStack.
+type VStack struct {
+	Align float32 // horizontal alignment of children. 0 left, .5 center, 1 right
+	Gap   float32 // space between children
+	Pad   float32 // space around the children, for sizing the container
+}
+
+func (l VStack) Layout(d *Dob) {
+	stack(d, false, l.Align, l.Gap, l.Pad)
+}
+
+// stack implements HStack and VStack. Works on the main (along) and cross axes.
+func stack(d *Dob, horizontal bool, align, gap, pad float32) {
+	var along, cross float32
+	n := 0
+	layoutEach(d, func(b *Dob, w, h float32) {
+		if !horizontal {
+			w, h = h, w
+		}
+		if n > 0 {
+			along += gap
+		}
+		along += w
+		if h > cross {
+			cross = h
+		}
+		n++
+	})
+	if horizontal {
+		layoutFit(d, along+2*pad, cross+2*pad)
+	} else {
+		layoutFit(d, cross+2*pad, along+2*pad)
+	}
+
+	pos := -along / 2
+	layoutEach(d, func(b *Dob, w, h float32) {
+		if !horizontal {
+			w, h = h, w
+		}
+		off := (align - .5) * (cross - h)
+		if horizontal {
+			b.Px, b.Py = d.Px+pos+w/2, d.Py+off
+		} else {
+			b.Px, b.Py = d.Px+off, d.Py+pos+w/2
+		}
+		pos += w + gap
+	})
+}
+
+// Grid lays out the children of a dob in rows of Cols equal cells, in insertion order,
+// centered on the dob. Cells fit the largest child. Sizes color rect containers to fit.
+type Grid struct {
+	Cols int     // cells per row. defaults to 1
+	Gap  float32 // space between cells
+	Pad  float32 // space around the cells, for sizing the container
+}
+
+func (l Grid) Layout(d *Dob) {
+	cols := l.Cols
+	if cols < 1 {
+		cols = 1
+	}
+	var cw, ch float32
+	n := 0
+	layoutEach(d, func(b *Dob, w, h float32) {
+		if w > cw {
+			cw = w
+		}
+		if h > ch {
+			ch = h
+		}
+		n++
+	})
+	if n == 0 {
+		return
+	}
+	rows := (n + cols - 1) / cols
+	if n < cols {
+		cols = n
+	}
+	w := float32(cols)*cw + float32(cols-1)*l.Gap
+	h := float32(rows)*ch + float32(rows-1)*l.Gap
+	layoutFit(d, w+2*l.Pad, h+2*l.Pad)
+
+	i := 0
+	layoutEach(d, func(b *Dob, _, _ float32) {
+		col, row := i%cols, i/cols
+		b.Px = d.Px - w/2 + float32(col)*(cw+l.Gap) + cw/2
+		b.Py = d.Py - h/2 + float32(row)*(ch+l.Gap) + ch/2
+		i++
+	})
+}
+
+// layoutEach calls fn with the size of each visible, non empty child of d in insertion order
+func layoutEach(d *Dob, fn func(b *Dob, w, h float32)) {
+	d.dobs.Range(func(id int64, b *Dob) bool {
+		// we need this check to support racing Ans
+		if b == nil || b.Hidden {
+			return true
+		}
+		if w, h := b.size(); w > 0 || h > 0 {
+			fn(b, w, h)
+		}
+		return true
+	})
+}
+
+// layoutFit sizes a color rect container to w x h on the stage
+func layoutFit(d *Dob, w, h float32) {
+	if d.Texture == nil && d.Painter == nil && d.Scale != 0 {
+		d.D[0], d.D[1] = int32(w/d.Scale), int32(h/d.Scale)
+	}
+}
+
+// Resize sets the size of the stage, then resizes Root and the layers to match
+// and reapplies all anchors and layouts. Camera viewports scale with the stage,
+// and cameras keep their offset from the center of the stage.
+// Play and Tick call it when the view resizes.
+func (s *Stage) Resize(w, h int32) {
+	ow, oh := s.Root.D[0], s.Root.D[1]
+	if ow > 0 && oh > 0 {
+		sx, sy := float32(w)/float32(ow), float32(h)/float32(oh)
+		for _, c := range s.Cameras {
+			v := &c.Viewport
+			v.X, v.Y = int32(float32(v.X)*sx), int32(float32(v.Y)*sy)
+			v.W, v.H = int32(float32(v.W)*sx), int32(float32(v.H)*sy)
+			c.Px += float32(w-ow) / 2
+			c.Py += float32(h-oh) / 2
+		}
+	}
+	s.Root.D[0], s.Root.D[1] = w, h
+	s.Root.Px, s.Root.Py = float32(w)/2, float32(h)/2
+	s.Root.Relayout()
+	for _, l := range s.layers {
+		l.D[0], l.D[1] = w, h
+		l.Px, l.Py = float32(w)/2, float32(h)/2
+		l.Relayout()
+	}
+}
@@ -0,0 +1,152 @@
+package gas
+
+import "testing"
+
+// boxSpawn spawns a w x h color rect into ctx
+func boxSpawn(ctx *Dob, w, h int32) *Dob {
+	d := ctx.SpawnRect()
+	d.Scale = 1
+	d.D = [2]int32{w, h}
+	return d
+}
+
+// posCheck reports d when it is not at x, y
+func posCheck(t *testing.T, name string, d *Dob, x, y float32) {
+	t.Helper()
+	if d.Px != x || d.Py != y {
+		t.Errorf("%s at %v, %v, want %v, %v", name, d.Px, d.Py, x, y)
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	tests := []struct {
+		name   string
+		anchor Anchor
+		safe   Insets
+		x, y   float32
+	}{
+		{"top left", AnchorTopLeft, Insets{}, 50, 25},
+		{"center", AnchorCenter, Insets{}, 400, 300},
+		{"bottom right", AnchorBottomRight, Insets{}, 750, 575},
+		{"top right off", AnchorTopRight.Off(-16, 16), Insets{}, 734, 41},
+		{"two thirds", Anchor{X: 2. / 3, Y: 2. / 3}, Insets{}, 516.66666, 391.66666},
+		{"safe top left", AnchorTopLeft, Insets{Top: 20, Right: 10, Bottom: 40, Left: 30}, 80, 45},
+		{"safe bottom right", AnchorBottomRight, Insets{Top: 20, Right: 10, Bottom: 40, Left: 30}, 740, 535},
+		{"safe center", AnchorCenter, Insets{Top: 20, Right: 10, Bottom: 40, Left: 30}, 410, 290},
+		{"bleed ignores safe", AnchorTopLeft.Bleeds(), Insets{Top: 20, Right: 10, Bottom: 40, Left: 30}, 50, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stageTest()
+			s.SafeSet(tt.safe)
+			d := boxSpawn(s.Root, 100, 50)
+			d.Anchor(tt.anchor)
+			if d.Px < tt.x-1e-3 || d.Px > tt.x+1e-3 || d.Py < tt.y-1e-3 || d.Py > tt.y+1e-3 {
+				t.Errorf("at %v, %v, want %v, %v", d.Px, d.Py, tt.x, tt.y)
+			}
+		})
+	}
+}
+
+func TestAnchorResize(t *testing.T) {
+	s := stageTest()
+	corner := boxSpawn(s.Root, 100, 50)
+	corner.Anchor(AnchorBottomRight.Off(-10, -10))
+	inner := boxSpawn(corner, 10, 10)
+	inner.Anchor(AnchorTopLeft)
+	s.Resize(1280, 480)
+	posCheck(t, "corner", corner, 1220, 445)
+	posCheck(t, "inner", inner, 1175, 425)
+	s.SafeSet(Insets{Right: 20})
+	posCheck(t, "corner in the safe area", corner, 1200, 445)
+}
+
+func TestAnchorSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		texture bool
+		anchor  Anchor
+		d       [2]int32
+		scale   float32
+	}{
+		{"rect both", false, AnchorCenter.Size(.5, .25), [2]int32{400, 150}, 1},
+		{"rect width keeps aspect", false, AnchorCenter.Size(.5, 0), [2]int32{400, 200}, 1},
+		{"rect height keeps aspect", false, AnchorCenter.Size(0, .5), [2]int32{600, 300}, 1},
+		{"texture scales to fit", true, AnchorCenter.Size(1, 1), [2]int32{100, 50}, 8},
+		{"texture height", true, AnchorCenter.Size(0, 1), [2]int32{100, 50}, 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stageTest()
+			d := boxSpawn(s.Root, 100, 50)
+			if tt.texture {
+				d.Texture = &Texture{W: 100, H: 50}
+			}
+			d.Anchor(tt.anchor)
+			if d.D != tt.d || d.Scale != tt.scale {
+				t.Errorf("D %v scale %v, want %v scale %v", d.D, d.Scale, tt.d, tt.scale)
+			}
+			posCheck(t, "dob", d, 400, 300)
+		})
+	}
+}
+
+func TestStacks(t *testing.T) {
+	s := stageTest()
+	row := boxSpawn(s.Root, 0, 0)
+	row.Move(400, 300)
+	row.Layout = HStack{Align: 0, Gap: 5, Pad: 2}
+	a, b, c := boxSpawn(row, 10, 10), boxSpawn(row, 20, 20), boxSpawn(row, 30, 30)
+	boxSpawn(row, 40, 40).Hidden = true
+	boxSpawn(row, 0, 0) // empty
+	row.Relayout()
+	if row.D != [2]int32{74, 34} {
+		t.Errorf("row sized %v, want [74 34]", row.D)
+	}
+	posCheck(t, "a", a, 370, 290)
+	posCheck(t, "b", b, 390, 295)
+	posCheck(t, "c", c, 420, 300)
+
+	col := boxSpawn(s.Root, 0, 0)
+	col.Move(400, 300)
+	col.Layout = VStack{Align: 1, Gap: 5}
+	a, b, c = boxSpawn(col, 10, 10), boxSpawn(col, 20, 20), boxSpawn(col, 30, 30)
+	col.Relayout()
+	if col.D != [2]int32{30, 70} {
+		t.Errorf("col sized %v, want [30 70]", col.D)
+	}
+	posCheck(t, "a", a, 410, 270)
+	posCheck(t, "b", b, 405, 290)
+	posCheck(t, "c", c, 400, 320)
+}
+
+func TestGrid(t *testing.T) {
+	s := stageTest()
+	g := boxSpawn(s.Root, 0, 0)
+	g.Move(400, 300)
+	g.Layout = Grid{Cols: 2, Gap: 2, Pad: 1}
+	var cells []*Dob
+	for i := 0; i < 5; i++ {
+		cells = append(cells, boxSpawn(g, 10, 10))
+	}
+	cells[1].D[0] = 20 // widens every cell
+	g.Relayout()
+	if g.D != [2]int32{44, 36} {
+		t.Errorf("grid sized %v, want [44 36]", g.D)
+	}
+	want := [][2]float32{{389, 288}, {411, 288}, {389, 300}, {411, 300}, {389, 312}}
+	for i, c := range cells {
+		posCheck(t, "cell", c, want[i][0], want[i][1])
+	}
+
+	// fewer children than columns center as one row
+	one := boxSpawn(s.Root, 0, 0)
+	one.Move(400, 300)
+	one.Layout = Grid{Cols: 4}
+	only := boxSpawn(one, 10, 10)
+	one.Relayout()
+	if one.D != [2]int32{10, 10} {
+		t.Errorf("one cell grid sized %v, want [10 10]", one.D)
+	}
+	posCheck(t, "only", only, 400, 300)
+}
@@ -0,0 +1,95 @@
+package gas
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+)
+
+// Logger logs messages with key value pairs, eg. Warn("no audio", "err", err).
+// *slog.Logger satisfies it, as do most structured loggers with a thin adapter.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Error(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+}
+
+// LogShared logs for views and stages that do not set their own. Writes info and up to stderr.
+var LogShared Logger = MakeStdLogger(log.New(os.Stderr, "gas ", log.LstdFlags), false)
+
+// StdLogger writes to a log.Logger as "LEVEL msg key=value ..."
+type StdLogger struct {
+	Debugs bool // log debug messages
+	log    *log.Logger
+}
+
+// MakeStdLogger returns a Logger that writes to l, with debug messages if debugs
+func MakeStdLogger(l *log.Logger, debugs bool) *StdLogger {
+	return &StdLogger{Debugs: debugs, log: l}
+}
+
+// Debug logs msg if Debugs
+func (l *StdLogger) Debug(msg string, args ...any) {
+	if l.Debugs {
+		l.write("DEBUG", msg, args)
+	}
+}
+
+// Error logs msg
+func (l *StdLogger) Error(msg string, args ...any) {
+	l.write("ERROR", msg, args)
+}
+
+// Info logs msg
+func (l *StdLogger) Info(msg string, args ...any) {
+	l.write("INFO", msg, args)
+}
+
+// Warn logs msg
+func (l *StdLogger) Warn(msg string, args ...any) {
+	l.write("WARN", msg, args)
+}
+
+// write formats the pairs in args after msg. A key without a value logs as !BADKEY like slog
+func (l *StdLogger) write(level, msg string, args []any) {
+	var b strings.Builder
+	b.WriteString(level)
+	b.WriteByte(' ')
+	b.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			fmt.Fprintf(&b, " !BADKEY=%v", args[i])
+			break
+		}
+		v := fmt.Sprint(args[i+1])
+		if strings.ContainsAny(v, " =\"") {
+			v = fmt.Sprintf("%q", v)
+		}
+		fmt.Fprintf(&b, " %v=%s", args[i], v)
+	}
+	l.log.Print(b.String())
+}
+
+// ErrAssetNotFound is the error for a font, image, sound or music file that does not exist.
+// Match it with errors.Is
+var ErrAssetNotFound = errors.New("asset not found")
+
+// ErrRender is the error when sdl fails to render text or make a texture. Match it with errors.Is
+var ErrRender = errors.New("render failed")
+
+// assetErr wraps err from loading the kind of asset at path, as ErrAssetNotFound if there is no file
+func assetErr(kind, path string, err error) error {
+	if _, statErr := os.Stat(path); errors.Is(statErr, fs.ErrNotExist) {
+		return fmt.Errorf("could not load %s at %s: %w", kind, path, ErrAssetNotFound)
+	}
+	return fmt.Errorf("could not load %s at %s: %v", kind, path, err)
+}
+
+// renderErr wraps the sdl error err as ErrRender
+func renderErr(what string, err error) error {
+	return fmt.Errorf("%w: %s: %v", ErrRender, what, err)
+}
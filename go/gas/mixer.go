@@ -0,0 +1,282 @@
+package gas
+
+import (
+	"errors"
+	"os"
+
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// AudioBackend plays sounds and music for an Audio. Volumes run from 0 to 1 and pans from -1 for left to 1 for right.
+// MixBackend plays through the sdl mixer, NullBackend plays nothing and RecordBackend logs what plays.
+type AudioBackend interface {
+	Channels() int                                      // how many sounds play at once
+	Finished(fn func(ch int))                           // calls fn, maybe from another thread, when the sound on a channel finishes or stops
+	MusicLoad(path string) (*Music, error)              // opens the music at path
+	MusicPlay(m *Music, loops int, vol float32) error   // streams m, looping it loops times or forever if -1
+	MusicPlaying() bool                                 // reports whether music plays
+	MusicStop()                                         // stops the music
+	MusicVolume(vol float32)                            // sets the music volume
+	Pan(ch int, pan float32)                            // pans the sound on channel ch
+	Play(w *Wav, ch int, vol, pan float32) (int, error) // plays w on channel ch or any free channel if -1. returns the channel
+	SoundLoad(path string) (*Wav, error)                // loads the sound at path
+	Stop(ch int)                                        // stops the sound on channel ch
+	Tick(tick int32)                                    // marks the start of a tick
+	Volume(ch int, vol float32)                         // sets the volume of the sound on channel ch
+}
+
+// audioBackend returns the backend for loading shared sounds and music
+func audioBackend() AudioBackend {
+	if AudioShared == nil {
+		return MakeNullBackend()
+	}
+	return AudioShared.Backend
+}
+
+// MixBackend plays through SDL_mixer
+type MixBackend struct {
+	channels int
+}
+
+// MakeMixBackend opens the audio device
+func MakeMixBackend() (*MixBackend, error) {
+	if err := sdl.InitSubSystem(sdl.INIT_AUDIO); err != nil {
+		return nil, err
+	}
+	if err := mix.OpenAudio(mix.DEFAULT_FREQUENCY, mix.DEFAULT_FORMAT, mix.DEFAULT_CHANNELS, 4096); err != nil {
+		sdl.QuitSubSystem(sdl.INIT_AUDIO)
+		return nil, err
+	}
+	return &MixBackend{channels: mix.AllocateChannels(AudioChannels)}, nil
+}
+
+func (b *MixBackend) Channels() int {
+	return b.channels
+}
+
+func (b *MixBackend) Finished(fn func(ch int)) {
+	mix.ChannelFinished(fn)
+}
+
+func (b *MixBackend) MusicLoad(path string) (*Music, error) {
+	mus, err := mix.LoadMUS(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Music{Music: mus, Volume: 1, path: path}, nil
+}
+
+func (b *MixBackend) MusicPlay(m *Music, loops int, vol float32) error {
+	if m.Music == nil {
+		return errors.New("music not loaded by the mixer")
+	}
+	mix.VolumeMusic(mixVolume(vol))
+	return m.Music.Play(loops)
+}
+
+func (b *MixBackend) MusicPlaying() bool {
+	return mix.PlayingMusic()
+}
+
+func (b *MixBackend) MusicStop() {
+	mix.HaltMusic()
+}
+
+func (b *MixBackend) MusicVolume(vol float32) {
+	mix.VolumeMusic(mixVolume(vol))
+}
+
+func (b *MixBackend) Pan(ch int, pan float32) {
+	// balance rather than a constant power law, so centered sounds keep full volume
+	left, right := uint8(0xff), uint8(0xff)
+	if pan > 0 {
+		left = uint8(0xff * (1 - pan))
+	} else {
+		right = uint8(0xff * (1 + pan))
+	}
+	mix.SetPanning(ch, left, right)
+}
+
+func (b *MixBackend) Play(w *Wav, ch int, vol, pan float32) (int, error) {
+	if w.Wav == nil {
+		return -1, errors.New("sound not loaded by the mixer")
+	}
+	ch, err := w.Wav.Play(ch, 0)
+	if err != nil {
+		return -1, err
+	}
+	// the sound starts on the next mix, so these apply before it is heard
+	mix.Volume(ch, mixVolume(vol))
+	b.Pan(ch, pan)
+	return ch, nil
+}
+
+func (b *MixBackend) SoundLoad(path string) (*Wav, error) {
+	chunk, err := mix.LoadWAV(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Wav{Wav: chunk, Volume: 1, path: path}, nil
+}
+
+func (b *MixBackend) Stop(ch int) {
+	mix.HaltChannel(ch)
+}
+
+func (b *MixBackend) Tick(tick int32) {}
+
+func (b *MixBackend) Volume(ch int, vol float32) {
+	mix.Volume(ch, mixVolume(vol))
+}
+
+// mixVolume converts a volume from 0 to 1 to the mixer range
+func mixVolume(v float32) int {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return mix.MAX_VOLUME
+	}
+	return int(v*mix.MAX_VOLUME + .5)
+}
+
+// NullBackend plays nothing, for machines without audio devices and for headless runs.
+// Sounds finish on the tick after they start. Music plays until stopped.
+// Loading only checks that the file exists.
+type NullBackend struct {
+	busy     []bool // channels playing
+	finished func(ch int)
+	music    bool
+}
+
+// MakeNullBackend returns a silent backend
+func MakeNullBackend() *NullBackend {
+	return &NullBackend{busy: make([]bool, AudioChannels)}
+}
+
+func (b *NullBackend) Channels() int {
+	return len(b.busy)
+}
+
+func (b *NullBackend) Finished(fn func(ch int)) {
+	b.finished = fn
+}
+
+func (b *NullBackend) MusicLoad(path string) (*Music, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return &Music{Volume: 1, path: path}, nil
+}
+
+func (b *NullBackend) MusicPlay(m *Music, loops int, vol float32) error {
+	b.music = true
+	return nil
+}
+
+func (b *NullBackend) MusicPlaying() bool {
+	return b.music
+}
+
+func (b *NullBackend) MusicStop() {
+	b.music = false
+}
+
+func (b *NullBackend) MusicVolume(vol float32) {}
+
+func (b *NullBackend) Pan(ch int, pan float32) {}
+
+func (b *NullBackend) Play(w *Wav, ch int, vol, pan float32) (int, error) {
+	if ch < 0 {
+		for i, busy := range b.busy {
+			if !busy {
+				ch = i
+				break
+			}
+		}
+		if ch < 0 {
+			return -1, errors.New("no free channels")
+		}
+	}
+	b.busy[ch] = true
+	return ch, nil
+}
+
+func (b *NullBackend) SoundLoad(path string) (*Wav, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return &Wav{Volume: 1, path: path}, nil
+}
+
+func (b *NullBackend) Stop(ch int) {
+	if ch >= 0 && ch < len(b.busy) && b.busy[ch] {
+		b.busy[ch] = false
+		if b.finished != nil {
+			b.finished(ch)
+		}
+	}
+}
+
+func (b *NullBackend) Tick(tick int32) {
+	for ch := range b.busy {
+		b.Stop(ch)
+	}
+}
+
+func (b *NullBackend) Volume(ch int, vol float32) {}
+
+// AudioEvent is a sound or music that started on a RecordBackend
+type AudioEvent struct {
+	Music  bool // music rather than a sound
+	Pan    float32
+	Sound  string // the path of the sound or music
+	Tick   int32
+	Volume float32
+}
+
+// RecordBackend logs the sounds and music that start, so tests can assert
+// eg. that the splash played on the tick the frog entered the water.
+// It passes everything on to another backend, silent by default.
+type RecordBackend struct {
+	AudioBackend
+	Events []AudioEvent
+	tick   int32
+}
+
+// MakeRecordBackend returns a backend that logs and passes on to b, or to a NullBackend if b is nil
+func MakeRecordBackend(b AudioBackend) *RecordBackend {
+	if b == nil {
+		b = MakeNullBackend()
+	}
+	return &RecordBackend{AudioBackend: b}
+}
+
+func (b *RecordBackend) MusicPlay(m *Music, loops int, vol float32) error {
+	b.Events = append(b.Events, AudioEvent{Music: true, Sound: m.path, Tick: b.tick, Volume: vol})
+	return b.AudioBackend.MusicPlay(m, loops, vol)
+}
+
+func (b *RecordBackend) Play(w *Wav, ch int, vol, pan float32) (int, error) {
+	ch, err := b.AudioBackend.Play(w, ch, vol, pan)
+	if err == nil {
+		b.Events = append(b.Events, AudioEvent{Pan: pan, Sound: w.path, Tick: b.tick, Volume: vol})
+	}
+	return ch, err
+}
+
+func (b *RecordBackend) Tick(tick int32) {
+	b.tick = tick
+	b.AudioBackend.Tick(tick)
+}
+
+// Played returns the events for the sound or music at path
+func (b *RecordBackend) Played(path string) (events []AudioEvent) {
+	for _, e := range b.Events {
+		if e.Sound == path {
+			events = append(events, e)
+		}
+	}
+	return events
+}
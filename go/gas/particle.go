@@ -0,0 +1,319 @@
+package gas
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// EmitShape is the region of the emitter dob where new particles appear
+type EmitShape int
+
+const (
+	EmitPoint  EmitShape = iota // at the emitter position
+	EmitLine                    // along a horizontal line of length W centered on the emitter
+	EmitCircle                  // within a circle of radius W around the emitter
+	EmitRect                    // within a W x H rect centered on the emitter
+)
+
+// Curve maps the life of a particle (0 to 1) to a value.
+// Keys space evenly over the life and values lerp between them.
+type Curve []float32
+
+// At samples the curve at t
+func (c Curve) At(t float32) float32 {
+	if len(c) == 0 {
+		return 1
+	}
+	i, f := curveKey(len(c), t)
+	if f == 0 {
+		return c[i]
+	}
+	return c[i] + (c[i+1]-c[i])*f
+}
+
+// ColorCurve maps the life of a particle (0 to 1) to a color. See Curve.
+type ColorCurve []sdl.Color
+
+// At samples the curve at t
+func (c ColorCurve) At(t float32) sdl.Color {
+	if len(c) == 0 {
+		return sdl.Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	}
+	i, f := curveKey(len(c), t)
+	if f == 0 {
+		return c[i]
+	}
+	return colorLerp(c[i], c[i+1], f)
+}
+
+// curveKey returns the index of the key before t and the fraction to the next key
+func curveKey(n int, t float32) (int, float32) {
+	if n == 1 || t <= 0 {
+		return 0, 0
+	}
+	if t >= 1 {
+		return n - 1, 0
+	}
+	pos := t * float32(n-1)
+	i := int(pos)
+	return i, pos - float32(i)
+}
+
+// EmitCfg configures an Emitter. Zero values mean "none" except where noted.
+type EmitCfg struct {
+	Shape    EmitShape     // region where particles appear
+	W        float32       // width of the line or rect, radius of the circle
+	H        float32       // height of the rect
+	Burst    int           // particles emitted at once on the first tick
+	Rate     float32       // particles per second emitted continuously
+	Duration time.Duration // how long to emit. 0 emits until Stop
+	Max      int           // size of the particle pool. defaults to 1000
+	Life     time.Duration // lifetime of each particle
+	LifeVar  time.Duration // random +/- variance on Life
+	Speed    float32       // initial speed in px/sec
+	SpeedVar float32       // random +/- variance on Speed
+	Dir      float64       // initial direction in degrees (0 is right, 90 is down)
+	DirVar   float64       // random +/- variance on Dir
+	Gravity  [2]float32    // acceleration in px/sec^2
+	Drag     float32       // fraction of velocity lost per second
+	Spin     float64       // rotation in degrees/sec
+	SpinVar  float64       // random +/- variance on Spin
+	Scale    Curve         // scale over life. defaults to 1
+	Color    ColorCurve    // color mod over life. defaults to white
+}
+
+// particle is a pooled, lightweight stand-in for a Dob
+type particle struct {
+	age   float32
+	angle float64
+	life  float32
+	spin  float64
+	vx    float32
+	vy    float32
+	x     float32
+	y     float32
+}
+
+// Emitter spawns, simulates and paints particles for its dob.
+// Particles live in a fixed pool and paint with a single batched draw call.
+// The Emitter is also the An that drives the simulation. It completes once
+// it stops emitting and the last particle dies, so chain .Exit() to clean up.
+type Emitter struct {
+	BaseAn
+	Cfg     EmitCfg
+	carry   float32    // fractional particles owed to Rate
+	idxs    []int32    // index buffer for the batched draw
+	live    int        // particles [0:live] in the pool are alive
+	pool    []particle // particle pool
+	stopped bool
+	verts   []sdl.Vertex // vertex buffer for the batched draw
+}
+
+// Emitter spawns a dob that emits particles of the texture at path (or color
+// rects if path is ""). Position, scale and zoom of the dob apply to the particles.
+func (d *Dob) Emitter(path string, cfg EmitCfg) (em *Emitter, err error) {
+	dob, err := d.Spawn(path)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Max <= 0 {
+		cfg.Max = 1000
+	}
+	anID++
+	em = &Emitter{
+		BaseAn: BaseAn{id: anID, dob: dob, anSet: nil, Duration: int64(cfg.Duration), Easer: EaseNone},
+		Cfg:    cfg,
+		idxs:   make([]int32, 0, cfg.Max*6),
+		pool:   make([]particle, cfg.Max),
+		verts:  make([]sdl.Vertex, 0, cfg.Max*4),
+	}
+	dob.Painter = em
+	dob.AnSetAdd(em)
+	return em, nil
+}
+
+// Live returns the number of live particles
+func (a *Emitter) Live() int {
+	return a.live
+}
+
+// Stop ends emission. Live particles run out their lifetimes.
+func (a *Emitter) Stop() {
+	a.stopped = true
+}
+
+// Burst emits n particles immediately (on the next tick)
+func (a *Emitter) Burst(n int) {
+	a.carry += float32(n)
+}
+
+func (a *Emitter) Tick(tick int32) bool {
+	dt := float32(a.dob.Stage.DurationPerTick) / float32(time.Second)
+	if a.StartTick == 0 {
+		a.StartTick = tick
+		a.carry += float32(a.Cfg.Burst)
+	}
+
+	// emit
+	if !a.stopped {
+		if pct, _ := a.PC(tick); a.Duration != 0 && pct == 1 {
+			a.stopped = true
+		} else {
+			a.carry += a.Cfg.Rate * dt
+		}
+	}
+	// ticks round down to whole nanoseconds, so allow a little short of 1.
+	// else 30 a second at 30 ticks a second emits one short each second
+	for ; a.carry > 1-1e-4; a.carry-- {
+		a.emit()
+	}
+
+	// simulate
+	drag := 1 - a.Cfg.Drag*dt
+	if drag < 0 {
+		drag = 0
+	}
+	for i := 0; i < a.live; i++ {
+		p := &a.pool[i]
+		p.age += dt
+		if p.age >= p.life {
+			a.live--
+			a.pool[i] = a.pool[a.live]
+			i--
+			continue
+		}
+		p.vx = (p.vx + a.Cfg.Gravity[0]*dt) * drag
+		p.vy = (p.vy + a.Cfg.Gravity[1]*dt) * drag
+		p.x += p.vx * dt
+		p.y += p.vy * dt
+		p.angle += p.spin * float64(dt)
+	}
+
+	return a.stopped && a.live == 0
+}
+
+// emit takes a particle from the pool and launches it
+func (a *Emitter) emit() {
+	if a.live == len(a.pool) {
+		return
+	}
+	c := &a.Cfg
+	p := &a.pool[a.live]
+	a.live++
+
+	p.x, p.y = a.dob.Px, a.dob.Py
+	switch c.Shape {
+	case EmitLine:
+		p.x += c.W * (rand.Float32() - .5)
+	case EmitCircle:
+		r := c.W * float32(math.Sqrt(rand.Float64()))
+		t := 2 * math.Pi * rand.Float64()
+		p.x += r * float32(math.Cos(t))
+		p.y += r * float32(math.Sin(t))
+	case EmitRect:
+		p.x += c.W * (rand.Float32() - .5)
+		p.y += c.H * (rand.Float32() - .5)
+	}
+
+	speed := c.Speed + c.SpeedVar*(2*rand.Float32()-1)
+	dir := (c.Dir + c.DirVar*(2*rand.Float64()-1)) * math.Pi / 180
+	p.vx = speed * float32(math.Cos(dir))
+	p.vy = speed * float32(math.Sin(dir))
+	p.age = 0
+	p.life = float32(c.Life+time.Duration(float64(c.LifeVar)*(2*rand.Float64()-1))) / float32(time.Second)
+	p.angle = a.dob.angle
+	p.spin = c.Spin + c.SpinVar*(2*rand.Float64()-1)
+}
+
+// Paint implements Painter. Builds one quad per particle and draws them all at once.
+// Falls back to one copy per particle where SDL lacks RenderGeometry (before 2.0.18).
+func (a *Emitter) Paint(d *Dob) {
+	if a.live == 0 {
+		return
+	}
+	d.Stage.view.Renderer.SetDrawBlendMode(d.Blend.sdl())
+	if d.Texture != nil {
+		d.Texture.blendSet(d.Blend)
+	}
+	if d.Stage.view.noGeometry {
+		a.paintEach(d)
+		return
+	}
+	a.verts = a.verts[:0]
+	a.idxs = a.idxs[:0]
+	xf := &d.Stage.xf
+	hw := xf.zoom * d.Scale * d.zoom * float32(d.D[0]) / 2
+	hh := xf.zoom * d.Scale * d.zoom * float32(d.D[1]) / 2
+	for i := 0; i < a.live; i++ {
+		p := &a.pool[i]
+		t := p.age / p.life
+		s := a.Cfg.Scale.At(t)
+		color := a.Cfg.Color.At(t)
+		if d.Texture == nil {
+			color = modC(d.FillC, color)
+		}
+		x, y := xf.apply(p.x, p.y)
+		sin, cos := math.Sincos((p.angle + xf.rot) * math.Pi / 180)
+		ax, ay := hw*s*float32(cos), hw*s*float32(sin)
+		bx, by := -hh*s*float32(sin), hh*s*float32(cos)
+		n := int32(len(a.verts))
+		a.verts = append(a.verts,
+			sdl.Vertex{Position: sdl.FPoint{X: x - ax - bx, Y: y - ay - by}, Color: color, TexCoord: sdl.FPoint{X: 0, Y: 0}},
+			sdl.Vertex{Position: sdl.FPoint{X: x + ax - bx, Y: y + ay - by}, Color: color, TexCoord: sdl.FPoint{X: 1, Y: 0}},
+			sdl.Vertex{Position: sdl.FPoint{X: x + ax + bx, Y: y + ay + by}, Color: color, TexCoord: sdl.FPoint{X: 1, Y: 1}},
+			sdl.Vertex{Position: sdl.FPoint{X: x - ax + bx, Y: y - ay + by}, Color: color, TexCoord: sdl.FPoint{X: 0, Y: 1}},
+		)
+		a.idxs = append(a.idxs, n, n+1, n+2, n, n+2, n+3)
+	}
+	var tex *sdl.Texture
+	if d.Texture != nil {
+		tex = d.Texture.SDLTexture
+	}
+	if err := d.Stage.view.Renderer.RenderGeometry(tex, a.verts, a.idxs); err != nil {
+		d.Stage.view.noGeometry = true
+		a.paintEach(d)
+	}
+}
+
+// paintEach paints particles one at a time
+func (a *Emitter) paintEach(d *Dob) {
+	r := d.Stage.view.Renderer
+	xf := &d.Stage.xf
+	dst := &d.Stage.paintDstF
+	for i := 0; i < a.live; i++ {
+		p := &a.pool[i]
+		t := p.age / p.life
+		s := a.Cfg.Scale.At(t)
+		color := a.Cfg.Color.At(t)
+		x, y := xf.apply(p.x, p.y)
+		w := xf.zoom * d.Scale * d.zoom * float32(d.D[0]) * s
+		h := xf.zoom * d.Scale * d.zoom * float32(d.D[1]) * s
+		*dst = sdl.FRect{X: x - w/2, Y: y - h/2, W: w, H: h}
+		if d.Texture != nil {
+			d.Texture.SDLTexture.SetColorMod(color.R, color.G, color.B)
+			d.Texture.SDLTexture.SetAlphaMod(color.A)
+			r.CopyExF(d.Texture.SDLTexture, nil, dst, p.angle+xf.rot, nil, d.Flip)
+		} else {
+			color = modC(d.FillC, color)
+			r.SetDrawColor(color.R, color.G, color.B, color.A)
+			r.FillRectF(dst)
+		}
+	}
+	if d.Texture != nil {
+		d.Texture.SDLTexture.SetColorMod(0xff, 0xff, 0xff)
+		d.Texture.SDLTexture.SetAlphaMod(0xff)
+	}
+}
+
+// modC multiplies two colors like SDL color mod
+func modC(a, b sdl.Color) sdl.Color {
+	return sdl.Color{
+		R: uint8(uint16(a.R) * uint16(b.R) / 0xff),
+		G: uint8(uint16(a.G) * uint16(b.G) / 0xff),
+		B: uint8(uint16(a.B) * uint16(b.B) / 0xff),
+		A: uint8(uint16(a.A) * uint16(b.A) / 0xff),
+	}
+}
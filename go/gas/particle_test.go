@@ -0,0 +1,90 @@
+package gas
+
+import (
+	"testing"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestCurveAt(t *testing.T) {
+	tests := []struct {
+		c    Curve
+		t    float32
+		want float32
+	}{
+		{nil, .5, 1},
+		{Curve{2}, .7, 2},
+		{Curve{0, 10}, -1, 0},
+		{Curve{0, 10}, 0, 0},
+		{Curve{0, 10}, .25, 2.5},
+		{Curve{0, 10}, 1, 10},
+		{Curve{0, 10}, 2, 10},
+		{Curve{1, 1, .5}, .5, 1},
+		{Curve{1, 1, .5}, .75, .75},
+		{Curve{0, 4, 0, 8}, 1. / 6, 2},
+		{Curve{0, 4, 0, 8}, 5. / 6, 4},
+	}
+	for _, tt := range tests {
+		if got := tt.c.At(tt.t); got < tt.want-1e-5 || got > tt.want+1e-5 {
+			t.Errorf("%v.At(%v) = %v, want %v", tt.c, tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestColorCurveAt(t *testing.T) {
+	c := ColorCurve{SDLC(0x000000ff), SDLC(0xff804000)}
+	tests := []struct {
+		t    float32
+		want sdl.Color
+	}{
+		{0, SDLC(0x000000ff)},
+		{.5, sdl.Color{R: 0x7f, G: 0x40, B: 0x20, A: 0x7f}},
+		{1, SDLC(0xff804000)},
+	}
+	for _, tt := range tests {
+		if got := c.At(tt.t); got != tt.want {
+			t.Errorf("At(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+	if got := (ColorCurve{}).At(.5); got != SDLC(0xffffffff) {
+		t.Errorf("empty At = %v, want white", got)
+	}
+}
+
+func TestEmitterCounts(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   EmitCfg
+		burst int // extra particles from Burst before the first tick
+		ticks int
+		live  int
+		done  bool // the Emitter completed
+	}{
+		{"burst", EmitCfg{Burst: 10, Life: time.Minute}, 0, 1, 10, false},
+		{"burst plus Burst", EmitCfg{Burst: 10, Life: time.Minute}, 5, 3, 15, false},
+		{"rate", EmitCfg{Rate: 30, Life: time.Minute}, 0, 30, 30, false},
+		{"fractional rate", EmitCfg{Rate: 15, Life: time.Minute}, 0, 30, 15, false},
+		{"duration stops the rate", EmitCfg{Rate: 30, Duration: time.Second, Life: time.Minute}, 0, 60, 30, false},
+		{"max caps the pool", EmitCfg{Burst: 50, Rate: 300, Max: 20, Life: time.Minute}, 0, 10, 20, false},
+		{"particles die", EmitCfg{Rate: 30, Life: time.Second / 2}, 0, 60, 15, false},
+		{"burst dies and completes", EmitCfg{Burst: 10, Duration: time.Second / 30, Life: time.Second}, 0, 32, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stageTest()
+			em, err := s.Root.Emitter("", tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			em.Burst(tt.burst)
+			done := false
+			for tick := int32(1); tick <= int32(tt.ticks) && !done; tick++ {
+				done = em.Tick(tick)
+			}
+			if em.Live() != tt.live || done != tt.done {
+				t.Errorf("after %d ticks %d live and done %v, want %d and %v", tt.ticks, em.Live(), done, tt.live, tt.done)
+			}
+		})
+	}
+}
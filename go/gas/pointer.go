@@ -0,0 +1,308 @@
+package gas
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// PointerEvent describes a pointer event on a dob.
+// Events start at the topmost dob under the pointer and bubble up through ctx parents.
+type PointerEvent struct {
+	Button    Button  // MouseLeft, MouseRight... or MouseMove for Enter, Leave and Drag
+	Cancelled bool    // set by Cancel. stops bubbling
+	Current   *Dob    // the dob whose handler is running
+	DX        float32 // pointer movement since the last event, for Drag
+	DY        float32
+	Target    *Dob    // the dob under the pointer (or the dob that got the Down, for Drag)
+	Tick      int32   // tick when the event applied
+	X         float32 // pointer position in view coordinates
+	Y         float32
+}
+
+// Cancel stops the event from bubbling to the ctx of the current dob
+func (e *PointerEvent) Cancel() {
+	e.Cancelled = true
+}
+
+// PointerHandler handles a PointerEvent
+type PointerHandler func(e *PointerEvent)
+
+// pointer tracks hover and drag state for a stage
+type pointer struct {
+	down   *Dob   // target of the last Down. gets Drag events until Up
+	downB  Button // button of the last Down
+	hover  []*Dob // the dob under the pointer and its ctx chain
+	hoverQ []*Dob // scratch for the next hover chain
+	x      float32
+	y      float32
+}
+
+// pointerEvent dispatches an InputEvent to the dobs under the pointer. MakeStage subscribes it to Input.
+func (s *Stage) pointerEvent(e InputEvent) {
+	if e.Button < MouseMove || e.Button > MouseX2 {
+		return
+	}
+	p := &s.pointer
+	pe := PointerEvent{Button: e.Button, Tick: e.Tick, X: e.X, Y: e.Y, DX: e.X - p.x, DY: e.Y - p.y}
+	p.x, p.y = e.X, e.Y
+	target := s.Hit(e.X, e.Y)
+
+	// enter and leave do not bubble. each dob hears when the pointer crosses its own bounds.
+	p.hoverQ = p.hoverQ[:0]
+	for d := target; d != nil; d = d.ctx {
+		p.hoverQ = append(p.hoverQ, d)
+	}
+	for _, d := range p.hover {
+		if d.OnPointerLeave != nil && !dobIn(d, p.hoverQ) {
+			leave := pe
+			leave.Button, leave.Current, leave.Target = MouseMove, d, d
+			d.OnPointerLeave(&leave)
+		}
+	}
+	for i := len(p.hoverQ) - 1; i >= 0; i-- {
+		d := p.hoverQ[i]
+		if d.OnPointerEnter != nil && !dobIn(d, p.hover) {
+			enter := pe
+			enter.Button, enter.Current, enter.Target = MouseMove, d, d
+			d.OnPointerEnter(&enter)
+		}
+	}
+	p.hover, p.hoverQ = p.hoverQ, p.hover
+
+	switch {
+	case e.Button == MouseMove:
+		if p.down != nil {
+			pe.Target = p.down
+			pe.bubble(func(d *Dob) PointerHandler { return d.OnPointerDrag })
+		}
+	case e.Down:
+		p.down, p.downB = target, e.Button
+		pe.Target = target
+		pe.bubble(func(d *Dob) PointerHandler { return d.OnPointerDown })
+	default:
+		if e.Button == p.downB {
+			p.down = nil
+		}
+		pe.Target = target
+		pe.bubble(func(d *Dob) PointerHandler { return d.OnPointerUp })
+	}
+}
+
+// forget drops d from the hover chain and the drag target, eg. when d is released
+func (p *pointer) forget(d *Dob) {
+	if p.down == d {
+		p.down = nil
+	}
+	n := 0
+	for _, h := range p.hover {
+		if h != d {
+			p.hover[n] = h
+			n++
+		}
+	}
+	for i := n; i < len(p.hover); i++ {
+		p.hover[i] = nil
+	}
+	p.hover = p.hover[:n]
+}
+
+// bubble runs the handler of the target and each ctx in turn until one cancels
+func (e *PointerEvent) bubble(handler func(d *Dob) PointerHandler) {
+	for d := e.Target; d != nil && !e.Cancelled; d = d.ctx {
+		if h := handler(d); h != nil {
+			e.Current = d
+			h(e)
+		}
+	}
+}
+
+// dobIn reports whether d is in q
+func dobIn(d *Dob, q []*Dob) bool {
+	for _, b := range q {
+		if b == d {
+			return true
+		}
+	}
+	return false
+}
+
+// Hit returns the topmost dob under the view point x, y or nil.
+// Checks cameras, layers and children in reverse paint order, so the result
+// is the dob the user sees there. Dobs that paint nothing (eg. Root and
+// Layers) never hit, nor do Hidden dobs and their subtrees.
+func (s *Stage) Hit(x, y float32) *Dob {
+	if len(s.Cameras) == 0 {
+		base := xfIdentity(s.view.W, s.view.H)
+		return s.layersHit(x, y, &base)
+	}
+	for i := len(s.Cameras) - 1; i >= 0; i-- {
+		c := s.Cameras[i]
+		v := &c.Viewport
+		vx, vy := x-float32(v.X), y-float32(v.Y)
+		if vx < 0 || vy < 0 || vx >= float32(v.W) || vy >= float32(v.H) {
+			continue
+		}
+		base := c.xf()
+		var hit *Dob
+		if c.Target == nil {
+			hit = s.layersHit(vx, vy, &base)
+		} else {
+			hit = c.Target.hit(vx, vy, &base)
+		}
+		if hit != nil {
+			return hit
+		}
+	}
+	return nil
+}
+
+// layersHit hit tests Root and the layers in the reverse of layersPaint order
+func (s *Stage) layersHit(x, y float32, base *xf) *Dob {
+	rootDone := false
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		l := s.layers[i]
+		if !rootDone && l.Z < 0 {
+			if hit := s.Root.hit(x, y, base); hit != nil {
+				return hit
+			}
+			rootDone = true
+		}
+		lxf := base.parallax(l.Parallax)
+		if hit := l.hit(x, y, &lxf); hit != nil {
+			return hit
+		}
+	}
+	if !rootDone {
+		return s.Root.hit(x, y, base)
+	}
+	return nil
+}
+
+// hit returns the topmost dob of the subtree at render target point x, y or nil.
+// Points outside the clip rect of a Clip dob or the texture of a Cache miss the subtree.
+func (d *Dob) hit(x, y float32, xf *xf) *Dob {
+	if d.Hidden {
+		return nil
+	}
+	if d.Clip && !d.clipHit(x, y, xf) {
+		return nil
+	}
+	if d.Cache != nil {
+		cx, cy, w, h := d.cacheBounds()
+		if !quadHit(x, y, cx, cy, float32(w), float32(h), xf) {
+			return nil
+		}
+	}
+	// the last Paint may be stale, eg. under a Cache. sort the children as they stand
+	d.paintQSort()
+	for i := len(d.paintQ) - 1; i >= 0; i-- {
+		if hit := d.paintQ[i].hit(x, y, xf); hit != nil {
+			return hit
+		}
+	}
+	if d.Painter == nil && d.Texture == nil && d.FillC.A == 0 {
+		return nil
+	}
+	if d.hitTest(x, y, xf) {
+		return d
+	}
+	return nil
+}
+
+// clipHit reports whether the render target point x, y falls in the clip rect of d through xf.
+// Like clipPush, the rect is the unrotated dob as painted.
+func (d *Dob) clipHit(x, y float32, xf *xf) bool {
+	cx, cy := xf.apply(d.center())
+	k := xf.zoom * d.Scale * d.zoom
+	hw, hh := k*float32(d.D[0])/2, k*float32(d.D[1])/2
+	return x >= cx-hw && x < cx+hw && y >= cy-hh && y < cy+hh
+}
+
+// quadHit reports whether the render target point x, y falls in the w x h stage rect
+// centered on the stage point cx, cy, as painted through xf
+func quadHit(x, y, cx, cy, w, h float32, xf *xf) bool {
+	qx, qy := xf.apply(cx, cy)
+	sin, cos := math.Sincos(-xf.rot * math.Pi / 180)
+	dx, dy := x-qx, y-qy
+	lx := (dx*float32(cos) - dy*float32(sin)) / xf.zoom
+	ly := (dx*float32(sin) + dy*float32(cos)) / xf.zoom
+	return lx >= -w/2 && lx < w/2 && ly >= -h/2 && ly < h/2
+}
+
+// hitTest reports whether the render target point x, y falls on the dob as painted through xf.
+// Accounts for rotation, pivot, scale, zoom and flips. If HitAlpha is set, also tests the texture pixel.
+func (d *Dob) hitTest(x, y float32, xf *xf) bool {
+	cx, cy := xf.apply(d.center())
+	k := xf.zoom * d.Scale * d.zoom
+	if k == 0 {
+		return false
+	}
+
+	// rotate the point into the unrotated frame of the dob, then to texture pixels
+	sin, cos := math.Sincos(-(d.angle + xf.rot) * math.Pi / 180)
+	dx, dy := x-cx, y-cy
+	lx := (dx*float32(cos) - dy*float32(sin)) / k
+	ly := (dx*float32(sin) + dy*float32(cos)) / k
+	hw, hh := float32(d.D[0])/2, float32(d.D[1])/2
+	if lx < -hw || lx >= hw || ly < -hh || ly >= hh {
+		return false
+	}
+	if d.HitAlpha == 0 || d.Texture == nil {
+		return true
+	}
+	if d.Flip&sdl.FLIP_HORIZONTAL != 0 {
+		lx = -lx - 1
+	}
+	if d.Flip&sdl.FLIP_VERTICAL != 0 {
+		ly = -ly - 1
+	}
+	return d.Texture.alphaAt(int32(lx+hw), int32(ly+hh)) >= d.HitAlpha
+}
+
+// alphaAt returns the alpha of the texture pixel at x, y.
+// Loads an alpha mask from the image file on first use.
+// Textures without a file (eg. text) read as opaque.
+func (t *Texture) alphaAt(x, y int32) uint8 {
+	if t.alpha == nil {
+		t.alpha = []uint8{}
+		if t.path != "" {
+			t.alphaLoad()
+		}
+	}
+	if len(t.alpha) == 0 || x < 0 || y < 0 || x >= t.W || y >= t.H {
+		return 0xff
+	}
+	return t.alpha[y*t.W+x]
+}
+
+// alphaLoad reads the alpha channel of the image into the mask
+func (t *Texture) alphaLoad() {
+	surface := t.image
+	if surface == nil {
+		var err error
+		if surface, err = img.Load(t.path); err != nil {
+			return
+		}
+		defer surface.Free()
+	}
+	rgba, err := surface.ConvertFormat(uint32(sdl.PIXELFORMAT_RGBA32), 0)
+	if err != nil {
+		return
+	}
+	defer rgba.Free()
+	if rgba.W != t.W || rgba.H != t.H {
+		return
+	}
+	rgba.Lock()
+	defer rgba.Unlock()
+	pixels := rgba.Pixels()
+	t.alpha = make([]uint8, t.W*t.H)
+	for y := int32(0); y < t.H; y++ {
+		row := pixels[y*rgba.Pitch:]
+		for x := int32(0); x < t.W; x++ {
+			t.alpha[y*t.W+x] = row[4*x+3]
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package gas
+
+import "testing"
+
+// rectSpawn spawns an opaque w x h rect into ctx at x, y
+func rectSpawn(ctx *Dob, x, y float32, w, h int32) *Dob {
+	d := ctx.SpawnRect()
+	d.Scale = 1
+	d.D = [2]int32{w, h}
+	d.Move(x, y)
+	return d
+}
+
+func TestHitClip(t *testing.T) {
+	s := stageTest()
+	box := rectSpawn(s.Root, 400, 300, 100, 100)
+	box.FillC.A = 0
+	box.Clip = true
+	kid := rectSpawn(box, 440, 300, 40, 40) // spans 420 to 460, half outside the box
+
+	tests := []struct {
+		x    float32
+		want *Dob
+	}{
+		{400, nil},
+		{430, kid},
+		{449, kid},
+		{455, nil}, // painted outside the clip rect
+	}
+	for _, tt := range tests {
+		if got := s.Hit(tt.x, 300); got != tt.want {
+			t.Errorf("Hit(%v, 300) = %p, want %p", tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestHitOrder(t *testing.T) {
+	s := stageTest()
+	group := rectSpawn(s.Root, 400, 300, 200, 200)
+	group.FillC.A = 0
+	a := rectSpawn(group, 400, 300, 40, 40)
+	b := rectSpawn(group, 400, 300, 40, 40)
+	group.paintQSort() // as of a Paint
+
+	if got := s.Hit(400, 300); got != b {
+		t.Errorf("Hit = %p, want b %p", got, b)
+	}
+	// a cached subtree does not paint its children, so paintQ keeps the old order
+	group.CacheOn()
+	b.Z = -1
+	if got := s.Hit(400, 300); got != a {
+		t.Errorf("Hit after b.Z = -1 = %p, want a %p", got, a)
+	}
+	c := rectSpawn(group, 400, 300, 40, 40)
+	if got := s.Hit(400, 300); got != c {
+		t.Errorf("Hit after spawning c = %p, want c %p", got, c)
+	}
+	// the cache texture clips the subtree to the group
+	out := rectSpawn(group, 540, 300, 40, 40)
+	if got := s.Hit(540, 300); got != nil {
+		t.Errorf("Hit outside the cache = %p, want nil (out %p)", got, out)
+	}
+	group.CacheOff()
+	if got := s.Hit(540, 300); got != out {
+		t.Errorf("Hit without the cache = %p, want out %p", got, out)
+	}
+}
@@ -0,0 +1,194 @@
+package gas
+
+import (
+	"sync"
+
+	"github.com/goradd/maps"
+)
+
+// Pool recycles Dobs and the stock Ans to cut garbage collection churn.
+// Opt in with stage.Pool = MakePool().
+//
+// A pooled Dob returns to the pool when it Exits or when its ctx clears it.
+// A pooled An returns to the pool when it completes or when its dob clears it.
+// Either way, client code must drop its references at that point.
+// The stage drops its own, eg. the pointer hover and drag targets.
+// Code that cannot drop a reference in time can keep the ID with it:
+// a recycled Dob comes back with a new ID.
+//
+// Recycled objects reset at the end of the frame, since the tick that
+// releases an object may still be iterating over it.
+type Pool struct {
+	colors   pool[ColorAn, *ColorAn]
+	dobs     pool[Dob, *Dob]
+	exits    pool[ExitAn, *ExitAn]
+	moveTos  pool[MoveToAn, *MoveToAn]
+	mu       sync.Mutex // guards the free lists only. ids, anSets and dob trees are not safe for concurrent use
+	promises pool[PromiseAn, *PromiseAn]
+	resolves pool[ResolveAn, *ResolveAn]
+	sounds   pool[SoundAn, *SoundAn]
+	spins    pool[SpinAn, *SpinAn]
+	thens    pool[ThenAn, *ThenAn]
+	zooms    pool[ZoomAn, *ZoomAn]
+}
+
+// MakePool returns an empty Pool
+func MakePool() *Pool {
+	return &Pool{}
+}
+
+// pooled is the constraint for types in a pool. All of them embed BaseAn.
+type pooled[T any] interface {
+	*T
+	base() *BaseAn
+}
+
+// pool is a free list for one type
+type pool[T any, PT pooled[T]] struct {
+	dead []PT // released this frame. reset on flush.
+	free []PT // reset and ready for reuse
+}
+
+func (l *pool[T, PT]) get() PT {
+	n := len(l.free)
+	if n == 0 {
+		return PT(new(T))
+	}
+	t := l.free[n-1]
+	l.free = l.free[:n-1]
+	t.base().pooled = false
+	return t
+}
+
+func (l *pool[T, PT]) put(t PT) {
+	b := t.base()
+	if b.pooled {
+		return
+	}
+	b.pooled = true
+	l.dead = append(l.dead, t)
+}
+
+// flush resets dead objects and frees them.
+// Keeps the anSet map and (for Dobs) the child SliceMap to spare reallocation.
+func (l *pool[T, PT]) flush() {
+	for _, t := range l.dead {
+		anSet := t.base().anSet
+		for id := range anSet {
+			delete(anSet, id)
+		}
+		dob, isDob := any(t).(*Dob)
+		var dobs *maps.SliceMap[int64, *Dob]
+		if isDob && dob.dobs != nil {
+			dobs = dob.dobs
+			dobs.Clear()
+		}
+
+		var zero T
+		*t = zero
+		t.base().anSet = anSet
+		t.base().pooled = true
+		if isDob {
+			dob.dobs = dobs
+		}
+	}
+	l.free = append(l.free, l.dead...)
+	for i := range l.dead {
+		l.dead[i] = nil
+	}
+	l.dead = l.dead[:0]
+}
+
+// flush recycles everything released since the last flush. Stage.Play calls it once per frame.
+func (p *Pool) flush() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.colors.flush()
+	p.dobs.flush()
+	p.exits.flush()
+	p.moveTos.flush()
+	p.promises.flush()
+	p.resolves.flush()
+	p.sounds.flush()
+	p.spins.flush()
+	p.thens.flush()
+	p.zooms.flush()
+}
+
+// dobGet returns a clean Dob
+func (p *Pool) dobGet() *Dob {
+	if p == nil {
+		return &Dob{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dobs.get()
+}
+
+// dobPut recycles d. Call d.Clear first.
+func (p *Pool) dobPut(d *Dob) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.dobs.put(d)
+}
+
+// anGet returns a clean An from the free list that l selects
+func anGet[T any, PT pooled[T]](p *Pool, l func(p *Pool) *pool[T, PT]) PT {
+	if p == nil {
+		return PT(new(T))
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return l(p).get()
+}
+
+// anPut recycles a stock An. If chain, also recycles the Ans chained to it.
+// Ans of other types are left to the garbage collector.
+func (p *Pool) anPut(an An, chain bool) {
+	if p == nil {
+		return
+	}
+	if chain {
+		for _, next := range an.AnSet() {
+			p.anPut(next, true)
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch a := an.(type) {
+	case *ColorAn:
+		p.colors.put(a)
+	case *ExitAn:
+		p.exits.put(a)
+	case *MoveToAn:
+		p.moveTos.put(a)
+	case *PromiseAn:
+		p.promises.put(a)
+	case *ResolveAn:
+		p.resolves.put(a)
+	case *SoundAn:
+		p.sounds.put(a)
+	case *SpinAn:
+		p.spins.put(a)
+	case *ThenAn:
+		p.thens.put(a)
+	case *ZoomAn:
+		p.zooms.put(a)
+	}
+}
+
+func poolColors(p *Pool) *pool[ColorAn, *ColorAn]       { return &p.colors }
+func poolExits(p *Pool) *pool[ExitAn, *ExitAn]          { return &p.exits }
+func poolMoveTos(p *Pool) *pool[MoveToAn, *MoveToAn]    { return &p.moveTos }
+func poolPromises(p *Pool) *pool[PromiseAn, *PromiseAn] { return &p.promises }
+func poolResolves(p *Pool) *pool[ResolveAn, *ResolveAn] { return &p.resolves }
+func poolSounds(p *Pool) *pool[SoundAn, *SoundAn]       { return &p.sounds }
+func poolSpins(p *Pool) *pool[SpinAn, *SpinAn]          { return &p.spins }
+func poolThens(p *Pool) *pool[ThenAn, *ThenAn]          { return &p.thens }
+func poolZooms(p *Pool) *pool[ZoomAn, *ZoomAn]          { return &p.zooms }
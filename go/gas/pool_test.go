@@ -0,0 +1,95 @@
+package gas
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// stageTest returns a pooled stage on a view with no window, for tests that only tick
+func stageTest() *Stage {
+	s, _ := MakeStage(&View{W: 800, H: 600})
+	s.DurationPerTick = int64(time.Second / 30)
+	s.Pool = MakePool()
+	return s
+}
+
+func TestExitStopsChained(t *testing.T) {
+	s := stageTest()
+	d := s.Root.SpawnRect()
+	ran := 0
+	d.MoveTo(10, 10, 0, nil).Exit().Then(func(d *Dob) { ran++ }).MoveTo(20, 20, time.Second, nil)
+	d.ZoomTo(2, time.Second, nil).Then(func(d *Dob) { ran++ })
+	s.Tick(1)
+	if !d.released {
+		t.Fatal("dob not released")
+	}
+	if ran != 0 {
+		t.Errorf("%d Ans ran after the exit", ran)
+	}
+	if len(d.anSet) != 0 {
+		t.Errorf("anSet has %d Ans after the exit", len(d.anSet))
+	}
+	if n := len(s.Pool.thens.dead); n != 2 {
+		t.Errorf("recycled %d ThenAns, want 2", n)
+	}
+	if n := len(s.Pool.moveTos.dead); n != 2 {
+		t.Errorf("recycled %d MoveToAns, want 2", n)
+	}
+}
+
+// viewTest returns a view on the dummy video driver, or skips t without sdl
+func viewTest(t testing.TB) *View {
+	if os.Getenv("SDL_VIDEODRIVER") == "" {
+		os.Setenv("SDL_VIDEODRIVER", "dummy")
+	}
+	os.Setenv("SDL_AUDIODRIVER", "dummy")
+	if err := Init(); err != nil {
+		t.Skip("no sdl:", err)
+	}
+	t.Cleanup(Destroy)
+	v, err := MakeView(800, 600, "test")
+	if err != nil {
+		t.Skip("no view:", err)
+	}
+	t.Cleanup(v.Destroy)
+	return v
+}
+
+func TestPoolRecycle(t *testing.T) {
+	v := viewTest(t)
+	s, _ := MakeStage(v)
+	s.DurationPerTick = int64(time.Second / 30)
+	s.Pool = MakePool()
+	font, err := v.FontLoad("../fonts/Bangers-Regular.ttf", 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := s.Root.SpawnRect()
+	if err := d.TxtFillOut("Frogger", SDLC(0x00ff00ff), font, 2, SDLC(0x333333ff)); err != nil {
+		t.Fatal(err)
+	}
+	d.SpawnRect().SpawnRect()
+	d.MoveTo(100, 100, time.Second, nil)
+	d.ZoomTo(2, time.Second, nil).Then(func(d *Dob) {})
+	d.Exit()
+	s.Tick(1)
+	s.Pool.flush()
+
+	b := s.Root.SpawnRect()
+	if b != d {
+		t.Fatal("spawn did not recycle the exited dob")
+	}
+	if len(b.anSet) != 0 {
+		t.Errorf("recycled dob has %d Ans", len(b.anSet))
+	}
+	if b.dobs != nil && b.dobs.Len() != 0 {
+		t.Errorf("recycled dob has %d children", b.dobs.Len())
+	}
+	if b.Texture != nil || b.txt != "" || b.txtFont != nil {
+		t.Errorf("recycled dob kept its text %q", b.txt)
+	}
+	if b.released || b.ctx != s.Root {
+		t.Error("recycled dob is not live in its new ctx")
+	}
+}
@@ -0,0 +1,389 @@
+package gas
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Shape paints vector geometry for its dob: lines, polylines, polygons, circles,
+// ellipses, arcs and rounded rects. Spawn one with LineSpawn, CircleSpawn and friends.
+//
+// The outline is in dob pixels around the dob center (stage px at the Scale 1 the
+// spawners set), so it moves, scales, zooms and spins with the dob. The dob FillC fills
+// closed outlines (and the pie of an arc). StrokeC and StrokeW stroke the outline.
+// Tween the colors with FillTo and StrokeTo.
+//
+// Shapes draw as triangles in one batched call, falling back to row spans where
+// SDL lacks RenderGeometry (before 2.0.18, eg. headless builds).
+type Shape struct {
+	StrokeC sdl.Color // stroke color. transparent for none
+	StrokeW float32   // stroke width in dob pixels. 0 for none
+	builtW  float32   // StrokeW as of the last build
+	closed  bool      // the outline joins its last point to its first
+	dob     *Dob
+	fillN   int          // tris[:fillN] fill and tris[fillN:] stroke
+	pie     bool         // the fill includes the center, for arcs
+	pts     []sdl.FPoint // outline around the dob center
+	spans   []sdl.Rect   // scratch rows for painting without RenderGeometry
+	stale   bool         // the outline changed since the last build
+	tris    []sdl.FPoint // triangles around the dob center
+	verts   []sdl.Vertex // scratch vertices for painting
+}
+
+// shapeSpawn spawns a dob at x, y that paints a Shape with the outline pts
+func (d *Dob) shapeSpawn(x, y float32, pts []sdl.FPoint, closed bool) *Dob {
+	dob := d.SpawnRect()
+	dob.Px, dob.Py = x, y
+	dob.Scale = 1
+	s := &Shape{dob: dob}
+	dob.Painter = s
+	s.Set(pts, closed)
+	return dob
+}
+
+// LineSpawn spawns a white line w px wide from x0, y0 to x1, y1
+func (d *Dob) LineSpawn(x0, y0, x1, y1, w float32) *Dob {
+	cx, cy := (x0+x1)/2, (y0+y1)/2
+	dob := d.shapeSpawn(cx, cy, []sdl.FPoint{{X: x0 - cx, Y: y0 - cy}, {X: x1 - cx, Y: y1 - cy}}, false)
+	dob.Shape().stroke(w)
+	return dob
+}
+
+// PolylineSpawn spawns a white, 1 px polyline through pts around x, y
+func (d *Dob) PolylineSpawn(x, y float32, pts []sdl.FPoint) *Dob {
+	dob := d.shapeSpawn(x, y, pts, false)
+	dob.Shape().stroke(1)
+	return dob
+}
+
+// PolygonSpawn spawns a white polygon with corners pts around x, y. Concave is fine. Edges must not cross.
+func (d *Dob) PolygonSpawn(x, y float32, pts []sdl.FPoint) *Dob {
+	return d.shapeSpawn(x, y, pts, true)
+}
+
+// CircleSpawn spawns a white circle of radius r centered on x, y
+func (d *Dob) CircleSpawn(x, y, r float32) *Dob {
+	return d.EllipseSpawn(x, y, r, r)
+}
+
+// EllipseSpawn spawns a white ellipse with radii rx, ry centered on x, y
+func (d *Dob) EllipseSpawn(x, y, rx, ry float32) *Dob {
+	return d.shapeSpawn(x, y, arc(nil, rx, ry, 0, 360, false), true)
+}
+
+// ArcSpawn spawns a white, 1 px arc of radius r around x, y from angle from to angle to,
+// in degrees clockwise from 3 o'clock. Set FillC to fill the pie slice.
+func (d *Dob) ArcSpawn(x, y, r float32, from, to float64) *Dob {
+	dob := d.shapeSpawn(x, y, arc(nil, r, r, from, to, true), false)
+	dob.FillC.A = 0
+	s := dob.Shape()
+	s.pie = true
+	s.stroke(1)
+	return dob
+}
+
+// RoundRectSpawn spawns a white w x h rect with corners of radius r centered on x, y
+func (d *Dob) RoundRectSpawn(x, y, w, h, r float32) *Dob {
+	r = float32(math.Min(float64(r), math.Min(float64(w), float64(h))/2))
+	hw, hh := w/2, h/2
+	var pts []sdl.FPoint
+	for i, c := range [4][2]float32{{hw - r, hh - r}, {-hw + r, hh - r}, {-hw + r, -hh + r}, {hw - r, -hh + r}} {
+		n := len(pts)
+		pts = arc(pts, r, r, float64(90*i), float64(90*i+90), true)
+		for j := n; j < len(pts); j++ {
+			pts[j].X += c[0]
+			pts[j].Y += c[1]
+		}
+	}
+	return d.shapeSpawn(x, y, pts, true)
+}
+
+// Shape returns the Shape that paints d or nil
+func (d *Dob) Shape() *Shape {
+	s, _ := d.Painter.(*Shape)
+	return s
+}
+
+// Set replaces the outline with pts around the dob center. closed joins the last point to the first.
+func (s *Shape) Set(pts []sdl.FPoint, closed bool) {
+	s.pts = append(s.pts[:0], pts...)
+	s.closed = closed
+	s.stale = true
+	s.build()
+}
+
+// stroke sets a white stroke w px wide and no fill
+func (s *Shape) stroke(w float32) {
+	s.StrokeC = sdl.Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	s.StrokeW = w
+	if !s.pie {
+		s.dob.FillC.A = 0
+	}
+	s.build()
+}
+
+// arc appends points along an ellipse with radii rx, ry from angle from to angle to in degrees.
+// Spaces points about 4 px apart. If ends, includes the point at to, else stops short for closed loops.
+func arc(pts []sdl.FPoint, rx, ry float32, from, to float64, ends bool) []sdl.FPoint {
+	span := (to - from) * math.Pi / 180
+	n := int(math.Abs(span) * math.Max(float64(rx), float64(ry)) / 4)
+	if n < 12 {
+		n = 12
+	} else if n > 256 {
+		n = 256
+	}
+	last := n
+	if !ends {
+		last = n - 1
+	}
+	for i := 0; i <= last; i++ {
+		sin, cos := math.Sincos(from*math.Pi/180 + span*float64(i)/float64(n))
+		pts = append(pts, sdl.FPoint{X: rx * float32(cos), Y: ry * float32(sin)})
+	}
+	return pts
+}
+
+// build triangulates the fill and stroke if the outline or stroke width changed,
+// and sizes the dob to hold them
+func (s *Shape) build() {
+	if !s.stale && s.builtW == s.StrokeW {
+		return
+	}
+	s.stale = false
+	s.builtW = s.StrokeW
+	s.tris = s.tris[:0]
+
+	fill := s.pts
+	if s.pie {
+		fill = append([]sdl.FPoint{{}}, s.pts...)
+	}
+	if s.closed || s.pie {
+		s.tris = triangulate(s.tris, fill)
+	}
+	s.fillN = len(s.tris)
+	if s.StrokeW > 0 {
+		s.tris = strokeTris(s.tris, s.pts, s.closed, s.StrokeW/2)
+	}
+
+	var mx, my float32
+	for _, p := range s.tris {
+		mx = float32(math.Max(float64(mx), math.Abs(float64(p.X))))
+		my = float32(math.Max(float64(my), math.Abs(float64(p.Y))))
+	}
+	s.dob.D[0], s.dob.D[1] = int32(math.Ceil(float64(2*mx))), int32(math.Ceil(float64(2*my)))
+}
+
+// Paint implements Painter
+func (s *Shape) Paint(d *Dob) {
+	s.build()
+	fill, stroke := d.FillC.A > 0 && s.fillN > 0, s.StrokeC.A > 0 && len(s.tris) > s.fillN
+	if !fill && !stroke {
+		return
+	}
+	xf := &d.Stage.xf
+	cx, cy := d.center()
+	k := d.Scale * d.zoom
+	sin, cos := math.Sincos(d.angle * math.Pi / 180)
+	sn, cs := float32(sin), float32(cos)
+	s.verts = s.verts[:0]
+	for i, p := range s.tris {
+		c := s.StrokeC
+		if i < s.fillN {
+			if !fill {
+				continue
+			}
+			c = d.FillC
+		} else if !stroke {
+			break
+		}
+		px, py := p.X*k, p.Y*k
+		x, y := xf.apply(cx+px*cs-py*sn, cy+px*sn+py*cs)
+		s.verts = append(s.verts, sdl.Vertex{Position: sdl.FPoint{X: x, Y: y}, Color: c})
+	}
+
+	r := d.Stage.view.Renderer
+	r.SetDrawBlendMode(d.Blend.sdl())
+	if !d.Stage.view.noGeometry {
+		if r.RenderGeometry(nil, s.verts, nil) == nil {
+			return
+		}
+		d.Stage.view.noGeometry = true
+	}
+	// the fill vertices come first, if any
+	n := 0
+	if fill {
+		n = s.fillN
+		s.paintSpans(r, s.verts[:n], d.FillC)
+	}
+	if stroke {
+		s.paintSpans(r, s.verts[n:], s.StrokeC)
+	}
+}
+
+// paintSpans fills the triangles in verts with c one row at a time.
+// Samples pixel centers, so triangles that share an edge do not overlap.
+func (s *Shape) paintSpans(r *sdl.Renderer, verts []sdl.Vertex, c sdl.Color) {
+	s.spans = s.spans[:0]
+	for i := 0; i+2 < len(verts); i += 3 {
+		a, b, e := verts[i].Position, verts[i+1].Position, verts[i+2].Position
+		y0 := int32(math.Ceil(float64(min3(a.Y, b.Y, e.Y) - .5)))
+		y1 := int32(math.Ceil(float64(max3(a.Y, b.Y, e.Y) - .5)))
+		for y := y0; y < y1; y++ {
+			yc := float32(y) + .5
+			lo, hi := float32(math.Inf(1)), float32(math.Inf(-1))
+			for _, edge := range [3][2]sdl.FPoint{{a, b}, {b, e}, {e, a}} {
+				p, q := edge[0], edge[1]
+				if (p.Y <= yc) == (q.Y <= yc) {
+					continue
+				}
+				x := p.X + (yc-p.Y)*(q.X-p.X)/(q.Y-p.Y)
+				lo = float32(math.Min(float64(lo), float64(x)))
+				hi = float32(math.Max(float64(hi), float64(x)))
+			}
+			x0, x1 := int32(math.Ceil(float64(lo-.5))), int32(math.Ceil(float64(hi-.5)))
+			if x1 > x0 {
+				s.spans = append(s.spans, sdl.Rect{X: x0, Y: y, W: x1 - x0, H: 1})
+			}
+		}
+	}
+	if len(s.spans) > 0 {
+		r.SetDrawColor(c.R, c.G, c.B, c.A)
+		r.FillRects(s.spans)
+	}
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
+
+// triangulate appends triangles filling the simple polygon pts to tris, by ear clipping
+func triangulate(tris []sdl.FPoint, pts []sdl.FPoint) []sdl.FPoint {
+	n := len(pts)
+	if n < 3 {
+		return tris
+	}
+	// walk the polygon clockwise on screen (y down), so ears turn right
+	var area float32
+	for i := range pts {
+		p, q := pts[i], pts[(i+1)%n]
+		area += p.X*q.Y - q.X*p.Y
+	}
+	idx := make([]int, n)
+	for i := range idx {
+		if area > 0 {
+			idx[i] = i
+		} else {
+			idx[i] = n - 1 - i
+		}
+	}
+
+	for len(idx) > 3 {
+		found := false
+		for i := range idx {
+			a, b, c := pts[idx[(i+len(idx)-1)%len(idx)]], pts[idx[i]], pts[idx[(i+1)%len(idx)]]
+			if cross(a, b, c) <= 0 {
+				continue // reflex or flat
+			}
+			ear := true
+			for _, j := range idx {
+				p := pts[j]
+				if p != a && p != b && p != c && inTri(p, a, b, c) {
+					ear = false
+					break
+				}
+			}
+			if ear {
+				tris = append(tris, a, b, c)
+				idx = append(idx[:i], idx[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			// degenerate outline. fan what is left
+			for i := 1; i+1 < len(idx); i++ {
+				tris = append(tris, pts[idx[0]], pts[idx[i]], pts[idx[i+1]])
+			}
+			return tris
+		}
+	}
+	return append(tris, pts[idx[0]], pts[idx[1]], pts[idx[2]])
+}
+
+// cross returns the z of (b - a) x (c - b). Positive turns clockwise on screen.
+func cross(a, b, c sdl.FPoint) float32 {
+	return (b.X-a.X)*(c.Y-b.Y) - (b.Y-a.Y)*(c.X-b.X)
+}
+
+// inTri reports whether p lies in the clockwise triangle a, b, c
+func inTri(p, a, b, c sdl.FPoint) bool {
+	return cross(a, b, p) >= 0 && cross(b, c, p) >= 0 && cross(c, a, p) >= 0
+}
+
+// strokeTris appends triangles stroking the polyline pts hw px to either side to tris.
+// Joins are mitered, clamped to 4 hw for sharp corners.
+func strokeTris(tris []sdl.FPoint, pts []sdl.FPoint, closed bool, hw float32) []sdl.FPoint {
+	n := len(pts)
+	if n < 2 {
+		return tris
+	}
+	dir := func(i int) (float32, float32) {
+		p, q := pts[i], pts[(i+1)%n]
+		dx, dy := q.X-p.X, q.Y-p.Y
+		l := float32(math.Hypot(float64(dx), float64(dy)))
+		if l == 0 {
+			return 0, 0
+		}
+		return dx / l, dy / l
+	}
+	side := func(i int) (sdl.FPoint, sdl.FPoint) {
+		var ix, iy, ox, oy float32
+		if i > 0 || closed {
+			ix, iy = dir((i + n - 1) % n)
+		}
+		if i < n-1 || closed {
+			ox, oy = dir(i)
+		}
+		if ix == 0 && iy == 0 {
+			ix, iy = ox, oy
+		} else if ox == 0 && oy == 0 {
+			ox, oy = ix, iy
+		}
+		// the miter bisects the normals of the segments in and out
+		mx, my := -(iy + oy), ix+ox
+		l := float32(math.Hypot(float64(mx), float64(my)))
+		if l == 0 {
+			mx, my, l = -iy, ix, 1
+		}
+		mx, my = mx/l, my/l
+		k := hw
+		if dot := mx*-iy + my*ix; dot > 0 {
+			k = float32(math.Min(float64(hw/dot), float64(4*hw)))
+		}
+		p := pts[i]
+		return sdl.FPoint{X: p.X + mx*k, Y: p.Y + my*k}, sdl.FPoint{X: p.X - mx*k, Y: p.Y - my*k}
+	}
+
+	segs := n - 1
+	if closed {
+		segs = n
+	}
+	l0, r0 := side(0)
+	l, r := l0, r0
+	for i := 0; i < segs; i++ {
+		var nl, nr sdl.FPoint
+		if i+1 == n {
+			nl, nr = l0, r0
+		} else {
+			nl, nr = side(i + 1)
+		}
+		tris = append(tris, l, r, nl, r, nr, nl)
+		l, r = nl, nr
+	}
+	return tris
+}
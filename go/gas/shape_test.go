@@ -0,0 +1,112 @@
+package gas
+
+import (
+	"math"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// trisArea returns the summed area of the triangles in tris
+func trisArea(tris []sdl.FPoint) float64 {
+	var sum float64
+	for i := 0; i+2 < len(tris); i += 3 {
+		sum += math.Abs(float64(cross(tris[i], tris[i+1], tris[i+2]))) / 2
+	}
+	return sum
+}
+
+// pts returns the points of the flat list xy, eg. pts(0, 0, 10, 0)
+func pts(xy ...float32) []sdl.FPoint {
+	p := make([]sdl.FPoint, len(xy)/2)
+	for i := range p {
+		p[i] = sdl.FPoint{X: xy[2*i], Y: xy[2*i+1]}
+	}
+	return p
+}
+
+func TestTriangulate(t *testing.T) {
+	tests := []struct {
+		name string
+		pts  []sdl.FPoint
+		tris int
+		area float64
+	}{
+		{"too few", pts(0, 0, 10, 0), 0, 0},
+		{"triangle", pts(0, 0, 10, 0, 0, 10), 1, 50},
+		{"square clockwise", pts(0, 0, 10, 0, 10, 10, 0, 10), 2, 100},
+		{"square counterclockwise", pts(0, 0, 0, 10, 10, 10, 10, 0), 2, 100},
+		{"concave L", pts(0, 0, 20, 0, 20, 10, 10, 10, 10, 20, 0, 20), 4, 300},
+		{"concave arrow", pts(0, 0, 10, 5, 20, 0, 10, 20), 2, 150},
+		{"star", pts(10, 0, 12, 8, 20, 10, 12, 12, 10, 20, 8, 12, 0, 10, 8, 8), 6, 80},
+		{"collinear point", pts(0, 0, 5, 0, 10, 0, 10, 10, 0, 10), 3, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tris := triangulate(nil, tt.pts)
+			if len(tris) != 3*tt.tris {
+				t.Fatalf("%d triangles, want %d", len(tris)/3, tt.tris)
+			}
+			if a := trisArea(tris); math.Abs(a-tt.area) > 1e-3 {
+				t.Errorf("area %v, want %v", a, tt.area)
+			}
+			for i := 0; i < len(tris); i += 3 {
+				if cross(tris[i], tris[i+1], tris[i+2]) < 0 {
+					t.Errorf("triangle %v turns counterclockwise", tris[i:i+3])
+				}
+			}
+		})
+	}
+
+	// appends rather than replaces
+	tris := triangulate(pts(1, 1, 2, 2, 3, 3), pts(0, 0, 10, 0, 0, 10))
+	if len(tris) != 6 || tris[0] != (sdl.FPoint{X: 1, Y: 1}) {
+		t.Errorf("triangulate did not append to tris: %v", tris)
+	}
+}
+
+func TestStrokeTris(t *testing.T) {
+	tests := []struct {
+		name   string
+		pts    []sdl.FPoint
+		closed bool
+		hw     float32
+		tris   int
+		area   float64
+	}{
+		{"one point", pts(0, 0), false, 1, 0, 0},
+		{"segment", pts(0, 0, 10, 0), false, 1, 2, 20},
+		{"diagonal", pts(0, 0, 30, 40), false, 2, 2, 200},
+		{"right angle miter", pts(0, 0, 10, 0, 10, 10), false, 1, 4, 40},
+		{"straight through", pts(0, 0, 5, 0, 10, 0), false, 1, 4, 20},
+		{"closed square", pts(0, 0, 10, 0, 10, 10, 0, 10), true, 1, 8, 80},
+		{"repeated point", pts(0, 0, 10, 0, 10, 0), false, 1, 4, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tris := strokeTris(nil, tt.pts, tt.closed, tt.hw)
+			if len(tris) != 3*tt.tris {
+				t.Fatalf("%d triangles, want %d", len(tris)/3, tt.tris)
+			}
+			if a := trisArea(tris); math.Abs(a-tt.area) > 1e-3 {
+				t.Errorf("area %v, want %v", a, tt.area)
+			}
+		})
+	}
+}
+
+func TestStrokeMiterClamp(t *testing.T) {
+	// a hairpin would miter out to infinity. the join stays within 4 hw of the corner
+	hw := float32(2)
+	tris := strokeTris(nil, pts(0, 0, 100, 0, 0, 1), false, hw)
+	corner := sdl.FPoint{X: 100, Y: 0}
+	far := float32(0)
+	for _, p := range tris {
+		if d := float32(math.Hypot(float64(p.X-corner.X), float64(p.Y-corner.Y))); d < 50 && d > far {
+			far = d
+		}
+	}
+	if far < hw || far > 4*hw+1e-3 {
+		t.Errorf("corner join %v from the corner, want between %v and %v", far, hw, 4*hw)
+	}
+}
@@ -0,0 +1,80 @@
+package gas
+
+import "math"
+
+// Stats counts the work of one frame
+type Stats struct {
+	Allocs  uint64 // heap allocations per frame, averaged over the last second
+	Culled  int    // dobs skipped for being off the render target, zero size or transparent
+	Dirty   int    // dobs whose paint state changed since their last paint
+	Hidden  int    // dobs skipped, along with their subtrees, for Hidden
+	Painted int    // dobs that issued a draw call
+	Tick    int32  // the tick of the frame
+}
+
+// Stats returns the counters for the last complete frame. Safe to call from any goroutine.
+func (s *Stage) Stats() Stats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return s.statsLast
+}
+
+// paintState is everything that decides where a dob paints
+type paintState struct {
+	angle   float64
+	d       [2]int32
+	pivot   [2]float32
+	px      float32
+	py      float32
+	scale   float32
+	texture *Texture
+	xf      xf
+	zoom    float32
+}
+
+// paintStateCheck compares the paint state to the last paint.
+// On change, recomputes the dst rect and culling and returns true.
+func (d *Dob) paintStateCheck() bool {
+	xf := &d.Stage.xf
+	ps := paintState{
+		angle:   d.angle,
+		d:       d.D,
+		pivot:   d.Pivot,
+		px:      d.Px,
+		py:      d.Py,
+		scale:   d.Scale,
+		texture: d.Texture,
+		xf:      *xf,
+		zoom:    d.zoom,
+	}
+	if ps == d.painted {
+		return false
+	}
+	d.painted = ps
+
+	x, y := xf.apply(d.center())
+	w := xf.zoom * d.Scale * d.zoom * float32(d.D[0])
+	h := xf.zoom * d.Scale * d.zoom * float32(d.D[1])
+	d.dst.X, d.dst.Y, d.dst.W, d.dst.H = int32(x-w/2), int32(y-h/2), int32(w), int32(h)
+
+	// cull by the axis aligned bounds of the rotated rect
+	sin, cos := math.Sincos((d.angle + xf.rot) * math.Pi / 180)
+	ex := float32(math.Abs(float64(w)*cos)+math.Abs(float64(h)*sin)) / 2
+	ey := float32(math.Abs(float64(w)*sin)+math.Abs(float64(h)*cos)) / 2
+	d.culled = w <= 0 || h <= 0 || x+ex < 0 || y+ey < 0 || x-ex > 2*xf.cx || y-ey > 2*xf.cy
+	return true
+}
+
+// center returns the stage point at the center of the dob as painted.
+// It leaves Px, Py when the dob spins or zooms around a Pivot off its center.
+func (d *Dob) center() (float32, float32) {
+	if d.Pivot == [2]float32{} {
+		return d.Px, d.Py
+	}
+	w, h := d.size()
+	ox, oy := d.Pivot[0]*w, d.Pivot[1]*h
+	sin, cos := math.Sincos(d.angle * math.Pi / 180)
+	s, c := float32(sin), float32(cos)
+	// rotate and zoom the center around the pivot
+	return d.Px + ox + d.zoom*(-ox*c+oy*s), d.Py + oy + d.zoom*(-ox*s-oy*c)
+}
@@ -0,0 +1,146 @@
+package gas
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/trace"
+	"strings"
+	"time"
+)
+
+// phase is a part of a frame that Directors time and trace
+type phase int
+
+const (
+	phaseTick    phase = iota // Stage.Tick for the running scenes
+	phasePaint                // Stage.Paint for the running scenes, and compositing
+	phasePresent              // Renderer.Present, which waits on vsync
+	phaseFrame                // all of the above plus event polling, but not the sleep between frames
+	phaseN
+)
+
+var phaseNames = [phaseN]string{"tick", "paint", "present", "frame"}
+
+// timesBucket is the width of the histogram buckets
+const timesBucket = 250 * time.Microsecond
+
+// timesBuckets covers 0 to 100ms. slower frames count in the last bucket
+const timesBuckets = 400
+
+// FrameTimes collects histograms of how long each phase of a frame takes. see Director.TimesOn
+type FrameTimes struct {
+	hists [phaseN]histogram
+}
+
+// histogram counts durations in timesBucket wide buckets
+type histogram struct {
+	counts [timesBuckets + 1]int
+	max    time.Duration
+	n      int
+	sum    time.Duration
+}
+
+// TimesOn starts collecting frame times
+func (d *Director) TimesOn() *FrameTimes {
+	if d.Times == nil {
+		d.Times = &FrameTimes{}
+	}
+	return d.Times
+}
+
+// add counts a duration for phase p
+func (t *FrameTimes) add(p phase, dur time.Duration) {
+	h := &t.hists[p]
+	i := int(dur / timesBucket)
+	if i > timesBuckets {
+		i = timesBuckets
+	}
+	h.counts[i]++
+	h.n++
+	h.sum += dur
+	if dur > h.max {
+		h.max = dur
+	}
+}
+
+// quantile returns the upper bound of the bucket holding quantile q, or the max if less
+func (h *histogram) quantile(q float64) time.Duration {
+	target := int(q*float64(h.n) + .5)
+	seen := 0
+	for i, c := range h.counts {
+		seen += c
+		if seen >= target && c > 0 {
+			if d := time.Duration(i+1) * timesBucket; i < timesBuckets && d < h.max {
+				return d
+			}
+			return h.max
+		}
+	}
+	return h.max
+}
+
+// WriteTo writes a summary table, then the histogram of each phase in 1ms rows
+func (t *FrameTimes) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	fmt.Fprintf(&b, "%-8s %8s %9s %9s %9s %9s %9s\n", "phase", "frames", "mean", "p50", "p95", "p99", "max")
+	for p, h := range t.hists {
+		mean := time.Duration(0)
+		if h.n > 0 {
+			mean = h.sum / time.Duration(h.n)
+		}
+		fmt.Fprintf(&b, "%-8s %8d %7.2fms %7.2fms %7.2fms %7.2fms %7.2fms\n", phaseNames[p], h.n,
+			ms(mean), ms(h.quantile(.5)), ms(h.quantile(.95)), ms(h.quantile(.99)), ms(h.max))
+	}
+	for p, h := range t.hists {
+		if h.n == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s\n", phaseNames[p])
+		// merge the buckets into 1ms rows, skipping empty rows
+		per := int(time.Millisecond / timesBucket)
+		for lo := 0; lo <= timesBuckets; lo += per {
+			n := 0
+			for i := lo; i < lo+per && i <= timesBuckets; i++ {
+				n += h.counts[i]
+			}
+			if n == 0 {
+				continue
+			}
+			label := fmt.Sprintf("%3d-%dms", lo/per, lo/per+1)
+			if lo >= timesBuckets {
+				label = fmt.Sprintf("%dms+", timesBuckets/per)
+			}
+			bar := strings.Repeat("#", (n*60+h.n-1)/h.n)
+			fmt.Fprintf(&b, "%9s %8d %5.1f%% %s\n", label, n, 100*float64(n)/float64(h.n), bar)
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// phaseTimer times and traces one phase of a frame
+type phaseTimer struct {
+	region *trace.Region
+	start  time.Time
+}
+
+// phaseStart starts timing phase p, and a trace region for it. Regions cost little when not tracing.
+func (d *Director) phaseStart(p phase) phaseTimer {
+	return phaseTimer{region: trace.StartRegion(context.Background(), phaseNames[p]), start: time.Now()}
+}
+
+// phaseEnd ends the phase that t times. A phase may run more than once in a frame, eg. a tick per scene in transitions.
+func (d *Director) phaseEnd(p phase, t phaseTimer) {
+	t.region.End()
+	d.phaseDur[p] += time.Since(t.start)
+}
+
+// phaseCommit counts the time for phase p in the frame
+func (d *Director) phaseCommit(p phase) {
+	if d.Times != nil {
+		d.Times.add(p, d.phaseDur[p])
+	}
+	d.phaseDur[p] = 0
+}
@@ -0,0 +1,137 @@
+package ui
+
+import "frogger/gas"
+
+// Button is a box with a Label that calls OnClick when clicked, tapped or
+// accepted with keyboard focus. Tweens between the Normal, Hover and Pressed looks.
+type Button struct {
+	*gas.Dob        // the box
+	Label    *Label // the text, centered on the box
+	OnClick  func(b *Button)
+	focused  bool
+	hovered  bool
+	look     Look            // the look tweening in
+	lookAns  [3]int64        // ids of the Ans tweening the fill and zooms, to stop when the look changes
+	onHover  func(b *Button) // lets lists move focus to the pointer
+	pressed  bool
+	ui       *UI
+}
+
+// Button spawns a Button into ctx
+func (u *UI) Button(ctx *gas.Dob, txt string, onClick func(b *Button)) *Button {
+	b := &Button{Dob: box(ctx, u.Style.Normal.FillC), OnClick: onClick, ui: u}
+	b.Label = u.Label(b.Dob, txt)
+	b.Label.Anchor(gas.AnchorCenter)
+	b.look = u.Style.Normal
+	b.Zoom(b.look.Zoom)
+	b.Label.Zoom(b.look.Zoom)
+	b.fit()
+
+	b.OnPointerEnter = func(e *gas.PointerEvent) {
+		b.hovered = true
+		if b.onHover != nil {
+			b.onHover(b)
+		}
+		b.restyle()
+	}
+	b.OnPointerLeave = func(e *gas.PointerEvent) {
+		b.hovered, b.pressed = false, false
+		b.restyle()
+	}
+	b.OnPointerDown = func(e *gas.PointerEvent) {
+		if e.Button == gas.MouseLeft {
+			b.pressed = true
+			b.restyle()
+		}
+		e.Cancel()
+	}
+	b.OnPointerUp = func(e *gas.PointerEvent) {
+		if e.Button == gas.MouseLeft && b.pressed {
+			b.pressed = false
+			b.restyle()
+			b.Click()
+		}
+		e.Cancel()
+	}
+	b.Every(b.tick)
+	return b
+}
+
+// Set changes the text and resizes the box to fit. Relayout the container after.
+func (b *Button) Set(txt string) {
+	b.Label.Set(txt)
+	b.fit()
+}
+
+// Move places the button centered on x, y
+func (b *Button) Move(x, y float32) {
+	b.Px, b.Py = x, y
+	b.Label.Px, b.Label.Py = x, y
+}
+
+// Click calls OnClick
+func (b *Button) Click() {
+	if b.OnClick != nil {
+		b.OnClick(b)
+	}
+}
+
+// Focus shows or hides the keyboard focus look
+func (b *Button) Focus(on bool) {
+	b.focused = on
+	b.restyle()
+}
+
+// press flashes the pressed look and clicks, as for keyboard accept
+func (b *Button) press() {
+	b.lookTo(b.ui.Style.Pressed, func(*gas.Dob) { b.restyle() })
+	b.Click()
+}
+
+// fit sizes the box to the label
+func (b *Button) fit() {
+	pad := int32(b.ui.Style.Pad)
+	b.D[0] = b.Label.D[0] + 2*pad
+	b.D[1] = b.Label.D[1] + 2*pad
+}
+
+// restyle tweens to the look for the current state
+func (b *Button) restyle() {
+	s := &b.ui.Style
+	switch {
+	case b.pressed:
+		b.lookTo(s.Pressed, nil)
+	case b.hovered || b.focused:
+		b.lookTo(s.Hover, nil)
+	default:
+		b.lookTo(s.Normal, nil)
+	}
+}
+
+// lookTo tweens the fill and zoom of the box and label from where they are to l,
+// then calls then if not nil
+func (b *Button) lookTo(l Look, then func(*gas.Dob)) {
+	if l == b.look && then == nil {
+		return
+	}
+	b.AnRm(b.lookAns[0])
+	b.AnRm(b.lookAns[1])
+	b.Label.AnRm(b.lookAns[2])
+	s := &b.ui.Style
+	zoom := b.ZoomTo(l.Zoom, s.Tween, s.Easer)
+	b.lookAns = [3]int64{
+		b.FillTo(l.FillC, s.Tween, s.Easer).ID(),
+		zoom.ID(),
+		b.Label.ZoomTo(l.Zoom, s.Tween, s.Easer).ID(),
+	}
+	if then != nil {
+		zoom.Then(then)
+	}
+	b.look = l
+}
+
+// tick keeps the label on the box
+func (b *Button) tick(d *gas.Dob, tick int32) bool {
+	b.Label.Px, b.Label.Py = b.Px, b.Py
+	return false
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"frogger/gas"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// TextInput is a one line text field. With keyboard focus it takes typed text,
+// backspace deletes and enter calls OnEnter.
+type TextInput struct {
+	*gas.Dob                   // the box, sized for Max characters
+	Filter   func(r rune) rune // maps typed runes. return -1 to drop one. nil keeps all
+	Label    *Label            // the text, centered on the box
+	Max      int               // maximum length in runes
+	OnEnter  func(t *TextInput)
+	focused  bool
+	txt      []rune
+	ui       *UI
+}
+
+// Upper is a TextInput Filter for initials and the like. Keeps letters only, in upper case.
+func Upper(r rune) rune {
+	if !unicode.IsLetter(r) {
+		return -1
+	}
+	return unicode.ToUpper(r)
+}
+
+// TextInput spawns an empty TextInput for up to max runes into ctx
+func (u *UI) TextInput(ctx *gas.Dob, max int, filter func(r rune) rune) *TextInput {
+	t := &TextInput{Dob: box(ctx, u.Style.FieldC), Filter: filter, Max: max, ui: u}
+	t.Label = u.Label(t.Dob, "")
+	t.Label.Anchor(gas.AnchorCenter)
+	w, h, _ := u.Style.Font.SizeUTF8(strings.Repeat("W", max+1))
+	pad := int32(u.Style.Pad)
+	t.D[0] = int32(w) + 2*int32(u.Style.TxtOutW) + 2*pad
+	t.D[1] = int32(h) + 2*int32(u.Style.TxtOutW) + 2*pad
+	t.Every(func(d *gas.Dob, tick int32) bool {
+		t.Label.Px, t.Label.Py = t.Px, t.Py
+		return false
+	})
+	return t
+}
+
+// Move centers the field on x, y
+func (t *TextInput) Move(x, y float32) {
+	t.Px, t.Py = x, y
+	t.Label.Px, t.Label.Py = x, y
+}
+
+// Text returns the text
+func (t *TextInput) Text() string {
+	return string(t.txt)
+}
+
+// Set replaces the text. Applies neither Filter nor Max.
+func (t *TextInput) Set(txt string) {
+	t.txt = []rune(txt)
+	t.render()
+}
+
+// Focus starts or stops text input. While focused the field shows a caret.
+// Give the field keyboard focus with UI.FocusPush, or route keys to it from
+// another Focuser (see Modal).
+func (t *TextInput) Focus(on bool) {
+	if on == t.focused {
+		return
+	}
+	t.focused = on
+	if on {
+		sdl.StartTextInput()
+	} else {
+		sdl.StopTextInput()
+	}
+	t.render()
+}
+
+// Key implements Focuser
+func (t *TextInput) Key(e gas.InputEvent) bool {
+	switch {
+	case e.Button == gas.KeyText:
+		for _, r := range e.Text {
+			if t.Filter != nil {
+				r = t.Filter(r)
+			}
+			if r >= 0 && (t.Max <= 0 || len(t.txt) < t.Max) {
+				t.txt = append(t.txt, r)
+			}
+		}
+		t.render()
+	case e.Button == gas.Key(sdl.SCANCODE_BACKSPACE):
+		if len(t.txt) > 0 {
+			t.txt = t.txt[:len(t.txt)-1]
+			t.render()
+		}
+	case e.Button == gas.Key(sdl.SCANCODE_RETURN) || e.Button == gas.Key(sdl.SCANCODE_KP_ENTER):
+		if t.OnEnter != nil {
+			t.OnEnter(t)
+		}
+	case e.Button < gas.Key(sdl.SCANCODE_CAPSLOCK) && e.Button != gas.Key(sdl.SCANCODE_ESCAPE) && e.Button != gas.Key(sdl.SCANCODE_TAB):
+		// swallow the key presses behind typed text so they do not trigger actions.
+		// function, arrow and other keys that do not type go on
+	default:
+		return false
+	}
+	return true
+}
+
+// render updates the label with the text and caret
+func (t *TextInput) render() {
+	if t.focused && (t.Max <= 0 || len(t.txt) < t.Max) {
+		t.Label.Set(string(t.txt) + "_")
+	} else {
+		t.Label.Set(string(t.txt))
+	}
+}
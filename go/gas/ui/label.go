@@ -0,0 +1,35 @@
+package ui
+
+import "frogger/gas"
+
+// Label is a line of text in the ui style
+type Label struct {
+	*gas.Dob
+	txt string
+	ui  *UI
+}
+
+// Label spawns a Label into ctx
+func (u *UI) Label(ctx *gas.Dob, txt string) *Label {
+	d := ctx.SpawnRect()
+	l := &Label{Dob: d, ui: u}
+	l.Set(txt)
+	return l
+}
+
+// Set changes and renders the text
+func (l *Label) Set(txt string) {
+	l.txt = txt
+	if txt == "" {
+		txt = " " // ttf cannot render empty text
+	}
+	s := &l.ui.Style
+	if err := l.TxtFillOut(txt, s.TxtC, s.Font, s.TxtOutW, s.TxtOutC); err != nil {
+		l.Stage.Log.Error("label render", "txt", txt, "err", err)
+	}
+}
+
+// Text returns the text
+func (l *Label) Text() string {
+	return l.txt
+}
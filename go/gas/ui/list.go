@@ -0,0 +1,86 @@
+package ui
+
+import "frogger/gas"
+
+// VerticalList stacks Buttons in a column. With keyboard focus, the up and down
+// actions move focus between the buttons and accept clicks the focused one.
+// The pointer moves focus too.
+type VerticalList struct {
+	*gas.Dob           // invisible container sized to the column
+	Items    []*Button // in top to bottom order
+	focus    int       // index of the focused item or -1
+	ui       *UI
+}
+
+// VerticalList spawns an empty VerticalList into ctx
+func (u *UI) VerticalList(ctx *gas.Dob) *VerticalList {
+	l := &VerticalList{Dob: box(ctx, gas.SDLC(0x00000000)), focus: -1, ui: u}
+	l.D[0], l.D[1] = 0, 0
+	l.Layout = gas.VStack{Align: .5, Gap: u.Style.Gap}
+	return l
+}
+
+// Add appends a Button to the list
+func (l *VerticalList) Add(txt string, onClick func(b *Button)) *Button {
+	b := l.ui.Button(l.Dob, txt, onClick)
+	b.onHover = func(b *Button) {
+		for i, item := range l.Items {
+			if item == b {
+				l.FocusAt(i)
+			}
+		}
+	}
+	l.Items = append(l.Items, b)
+	l.Relayout()
+	return b
+}
+
+// Move centers the list on x, y
+func (l *VerticalList) Move(x, y float32) {
+	l.Px, l.Py = x, y
+	l.Relayout()
+}
+
+// FocusAt moves the keyboard focus to item i. -1 for none.
+func (l *VerticalList) FocusAt(i int) {
+	if l.focus >= 0 && l.focus < len(l.Items) {
+		l.Items[l.focus].Focus(false)
+	}
+	l.focus = i
+	if i >= 0 && i < len(l.Items) {
+		l.Items[i].Focus(true)
+	}
+}
+
+// Focused returns the focused item or nil
+func (l *VerticalList) Focused() *Button {
+	if l.focus < 0 || l.focus >= len(l.Items) {
+		return nil
+	}
+	return l.Items[l.focus]
+}
+
+// Key implements Focuser
+func (l *VerticalList) Key(e gas.InputEvent) bool {
+	n := len(l.Items)
+	if n == 0 {
+		return false
+	}
+	switch {
+	case l.ui.is(e, ActionUp):
+		if l.focus <= 0 {
+			l.FocusAt(n - 1)
+		} else {
+			l.FocusAt(l.focus - 1)
+		}
+	case l.ui.is(e, ActionDown):
+		l.FocusAt((l.focus + 1) % n)
+	case l.ui.is(e, ActionAccept):
+		if b := l.Focused(); b != nil {
+			b.press()
+		}
+	default:
+		return false
+	}
+	return true
+}
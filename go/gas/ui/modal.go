@@ -0,0 +1,98 @@
+package ui
+
+import "frogger/gas"
+
+// Modal is a dialog over a shade that blocks the pointer to everything beneath.
+// It takes keyboard focus when shown. Keys go to its TextInput, if any, then to its buttons.
+type Modal struct {
+	*gas.Dob                // the shade, covering the stage
+	Input    *TextInput     // optional. see Modal.TextInput
+	List     *VerticalList  // the buttons
+	OnCancel func(m *Modal) // called on the cancel action. nil ignores it
+	Panel    *gas.Dob       // the dialog box behind the contents
+	Title    *Label
+	closed   bool
+	ui       *UI
+}
+
+// Modal spawns a Modal covering ctx and gives it keyboard focus
+func (u *UI) Modal(ctx *gas.Dob, title string) *Modal {
+	m := &Modal{Dob: box(ctx, u.Style.ShadeC), ui: u}
+	m.Scale = 1
+	m.Anchor(gas.AnchorCenter.Size(1, 1))
+	swallow := func(e *gas.PointerEvent) { e.Cancel() }
+	m.OnPointerDown, m.OnPointerUp, m.OnPointerDrag = swallow, swallow, swallow
+
+	m.Panel = box(m.Dob, u.Style.PanelC)
+	m.Panel.Layout = gas.VStack{Align: .5, Gap: u.Style.Gap, Pad: 2 * u.Style.Pad}
+	m.Panel.Anchor(gas.AnchorCenter)
+	m.Title = u.Label(m.Panel, title)
+	m.List = u.VerticalList(m.Panel)
+	m.Relayout()
+	u.FocusPush(m)
+	return m
+}
+
+// Add appends a Button to the dialog
+func (m *Modal) Add(txt string, onClick func(b *Button)) *Button {
+	b := m.List.Add(txt, onClick)
+	if m.List.focus < 0 && m.Input == nil {
+		m.List.FocusAt(0)
+	}
+	m.Relayout()
+	return b
+}
+
+// TextInput adds a focused TextInput between the title and the buttons
+func (m *Modal) TextInput(max int, filter func(r rune) rune) *TextInput {
+	m.Input = m.ui.TextInput(m.Panel, max, filter)
+	m.Panel.InsertBefore(m.Input.Dob, m.List.Dob)
+	m.Input.Focus(true)
+	m.List.FocusAt(-1)
+	m.Relayout()
+	return m.Input
+}
+
+// Close removes the dialog and its keyboard focus. Closing again does nothing,
+// so the dob is not exited after the pool recycles it.
+func (m *Modal) Close() {
+	if m.closed {
+		return
+	}
+	m.closed = true
+	m.ui.FocusRm(m)
+	if m.Input != nil {
+		m.Input.Focus(false)
+	}
+	m.Exit()
+}
+
+// Key implements Focuser. Returns false for keys the dialog does not use, eg. hotkeys.
+// Focusers beneath the dialog never hear keys. see UI.inputEvent
+func (m *Modal) Key(e gas.InputEvent) bool {
+	if m.ui.is(e, ActionCancel) {
+		if m.OnCancel != nil {
+			m.OnCancel(m)
+		}
+		return true
+	}
+	if m.Input != nil && m.Input.focused {
+		if m.Input.Key(e) {
+			return true
+		}
+		if m.ui.is(e, ActionDown) && len(m.List.Items) > 0 {
+			m.Input.Focus(false)
+			m.List.FocusAt(0)
+			return true
+		}
+	}
+	if m.List.Key(e) {
+		return true
+	}
+	if m.Input != nil && m.ui.is(e, ActionUp) {
+		m.List.FocusAt(-1)
+		m.Input.Focus(true)
+		return true
+	}
+	return false
+}
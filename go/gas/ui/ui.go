@@ -0,0 +1,145 @@
+// ui is a small widget kit for menus and dialogs, composed from gas Dobs
+// and driven by the gas Input. Widgets paint wherever their ctx paints,
+// so put them on a layer with parallax 0 to keep them still under a moving camera.
+package ui
+
+import (
+	"time"
+
+	"frogger/gas"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// Actions the widgets read from the Input. MakeUI binds defaults for them.
+const (
+	ActionUp     = "ui.up"
+	ActionDown   = "ui.down"
+	ActionAccept = "ui.accept"
+	ActionCancel = "ui.cancel"
+)
+
+// Look is the animated appearance of a widget in one state
+type Look struct {
+	FillC sdl.Color
+	Zoom  float32
+}
+
+// Style configures the appearance of widgets
+type Style struct {
+	Font    *ttf.Font
+	TxtC    sdl.Color
+	TxtOutC sdl.Color
+	TxtOutW int
+	Normal  Look          // buttons at rest
+	Hover   Look          // buttons under the pointer or with keyboard focus
+	Pressed Look          // buttons held down
+	FieldC  sdl.Color     // text input boxes
+	PanelC  sdl.Color     // modal panels
+	ShadeC  sdl.Color     // modal backdrops. must not be transparent, since the shade blocks the pointer
+	Pad     float32       // space between text and the edge of its box
+	Gap     float32       // space between stacked widgets
+	Tween   time.Duration // duration of transitions between looks
+	Easer   gas.Ease
+}
+
+// StyleDefault returns a plain style for font
+func StyleDefault(font *ttf.Font) Style {
+	return Style{
+		Font:    font,
+		TxtC:    gas.SDLC(0xffffffff),
+		TxtOutC: gas.SDLC(0x333333ff),
+		TxtOutW: 2,
+		Normal:  Look{FillC: gas.SDLC(0x2e7d32ff), Zoom: 1},
+		Hover:   Look{FillC: gas.SDLC(0x43a047ff), Zoom: 1.1},
+		Pressed: Look{FillC: gas.SDLC(0x1b5e20ff), Zoom: .95},
+		FieldC:  gas.SDLC(0x222222ff),
+		PanelC:  gas.SDLC(0x113311ee),
+		ShadeC:  gas.SDLC(0x00000080),
+		Pad:     12,
+		Gap:     12,
+		Tween:   120 * time.Millisecond,
+		Easer:   gas.EaseOutSin,
+	}
+}
+
+// Focuser takes keyboard (and gamepad) events while it has focus.
+// Key returns true if it handled the event. Unhandled events pass down the focus stack.
+type Focuser interface {
+	Key(e gas.InputEvent) bool
+}
+
+// UI makes widgets for a stage and routes keys to the focused one
+type UI struct {
+	Stage *gas.Stage
+	Style Style
+	focus []Focuser // focus stack. the last gets events first
+}
+
+// MakeUI returns a UI for s and binds the default keys and buttons for the ui actions
+func MakeUI(s *gas.Stage, font *ttf.Font) *UI {
+	u := &UI{Stage: s, Style: StyleDefault(font)}
+	in := s.Input
+	in.Bind(ActionUp, gas.Key(sdl.SCANCODE_UP), gas.PadUp, gas.PadStickUp)
+	in.Bind(ActionDown, gas.Key(sdl.SCANCODE_DOWN), gas.Key(sdl.SCANCODE_TAB), gas.PadDown, gas.PadStickDown)
+	in.Bind(ActionAccept, gas.Key(sdl.SCANCODE_RETURN), gas.Key(sdl.SCANCODE_KP_ENTER), gas.Key(sdl.SCANCODE_SPACE), gas.PadA)
+	in.Bind(ActionCancel, gas.Key(sdl.SCANCODE_ESCAPE), gas.PadB)
+	in.On(u.inputEvent)
+	return u
+}
+
+// FocusPush gives f keyboard focus
+func (u *UI) FocusPush(f Focuser) {
+	u.FocusRm(f)
+	u.focus = append(u.focus, f)
+}
+
+// FocusRm takes keyboard focus from f
+func (u *UI) FocusRm(f Focuser) {
+	for i, g := range u.focus {
+		if g == f {
+			u.focus = append(u.focus[:i], u.focus[i+1:]...)
+			return
+		}
+	}
+}
+
+// Focused returns the Focuser with keyboard focus or nil
+func (u *UI) Focused() Focuser {
+	if len(u.focus) == 0 {
+		return nil
+	}
+	return u.focus[len(u.focus)-1]
+}
+
+// inputEvent passes presses and text down the focus stack until a Focuser handles them.
+// Handled events stop here, so subscribers after the UI (eg. game controls) do not also act on them.
+// Keys no Focuser handles (eg. global hotkeys) go on.
+func (u *UI) inputEvent(e gas.InputEvent) {
+	if !e.Down && e.Button != gas.KeyText {
+		return
+	}
+	for i := len(u.focus) - 1; i >= 0; i-- {
+		if u.focus[i].Key(e) {
+			u.Stage.Input.Cancel()
+			return
+		}
+		if _, ok := u.focus[i].(*Modal); ok {
+			// dialogs keep keys from the focusers beneath them
+			return
+		}
+	}
+}
+
+// is reports whether e triggers the action
+func (u *UI) is(e gas.InputEvent, action string) bool {
+	return e.Down && u.Stage.Input.Bound(action, e.Button)
+}
+
+// box spawns an untextured dob into ctx
+func box(ctx *gas.Dob, c sdl.Color) *gas.Dob {
+	d := ctx.SpawnRect()
+	d.FillC = c
+	return d
+}
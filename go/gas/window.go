@@ -0,0 +1,97 @@
+package gas
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// WindowMode is how a View occupies the screen
+type WindowMode int
+
+const (
+	WindowNormal     WindowMode = iota // a window with a frame
+	WindowBorderless                   // covers the desktop without a frame or a video mode change
+	WindowFullscreen                   // takes over the display at the size of the view
+)
+
+// windowFlags returns the sdl window flags for the view settings
+func (v *View) windowFlags() uint32 {
+	flags := uint32(sdl.WINDOW_SHOWN)
+	if v.Resizable {
+		flags |= sdl.WINDOW_RESIZABLE
+	}
+	if v.HighDPI {
+		flags |= sdl.WINDOW_ALLOW_HIGHDPI
+	}
+	switch v.Mode {
+	case WindowBorderless:
+		flags |= sdl.WINDOW_FULLSCREEN_DESKTOP
+	case WindowFullscreen:
+		flags |= sdl.WINDOW_FULLSCREEN
+	}
+	return flags
+}
+
+// ModeSet switches between a normal window, borderless and fullscreen.
+// Stages resize to match on the next frame (unless the view has a LogicalSize).
+func (v *View) ModeSet(mode WindowMode) error {
+	var flags uint32
+	switch mode {
+	case WindowBorderless:
+		flags = sdl.WINDOW_FULLSCREEN_DESKTOP
+	case WindowFullscreen:
+		flags = sdl.WINDOW_FULLSCREEN
+	}
+	if err := v.window.SetFullscreen(flags); err != nil {
+		return err
+	}
+	v.Mode = mode
+	return nil
+}
+
+// LogicalSize fixes the size of the view (and stages on it) at w x h whatever the window size.
+// The renderer scales the frame to fit the window, letterboxing to keep the aspect ratio.
+// If integer, it scales by whole multiples only for crisp pixel art.
+// LogicalSize(0, 0, false) goes back to sizing the view to the window.
+// Stages, with their layouts and cameras, resize to match on their next Tick.
+func (v *View) LogicalSize(w, h int32, integer bool) error {
+	if err := v.Renderer.SetLogicalSize(w, h); err != nil {
+		return err
+	}
+	if err := v.Renderer.SetIntegerScale(integer); err != nil {
+		return err
+	}
+	v.logical = w > 0 && h > 0
+	if v.logical {
+		v.W, v.H = w, h
+		return nil
+	}
+	v.sizeToWindow()
+	return nil
+}
+
+// sizeToWindow sizes the view to the window in points and scales drawing to
+// match the pixels of the renderer, which differ on high-DPI displays.
+// Returns true if the size changed.
+func (v *View) sizeToWindow() bool {
+	w, h := v.window.GetSize()
+	if pw, ph, err := v.Renderer.GetOutputSize(); err == nil && w > 0 && h > 0 {
+		v.Renderer.SetScale(float32(pw)/float32(w), float32(ph)/float32(h))
+	}
+	if w == v.W && h == v.H {
+		return false
+	}
+	v.W, v.H = w, h
+	return true
+}
+
+// windowEvent handles resizes of the view window.
+// Returns true if the view changed size, so the stages must resize.
+func (v *View) windowEvent(e *sdl.WindowEvent) bool {
+	if e.Event != sdl.WINDOWEVENT_SIZE_CHANGED || v.logical {
+		return false
+	}
+	if id, err := v.window.GetID(); err != nil || id != e.WindowID {
+		return false
+	}
+	return v.sizeToWindow()
+}
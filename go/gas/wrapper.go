@@ -10,33 +10,58 @@ type Texture struct {
 	SDLTexture *sdl.Texture
 	H          int32
 	W          int32
+	alpha      []uint8      // alpha mask for hit tests. see alphaAt
+	blend      Blend        // blend mode of SDLTexture. see blendSet
+	image      *sdl.Surface // decoded image file, shared by the textures for all renderers. see Assets
+	path       string       // image file, if loaded from one
 }
 
-// Wav wrapper for sounds that provides a simple interface
-// TODO consider getting rid of this abstraction for the native sdl.Wav
+// Wav is a sound decoded to memory for effects. Any number of copies play at once.
+// Load it with SoundLoad.
 type Wav struct {
-	View    *View
-	Wav     *mix.Chunk
-	playing bool
-	channel int
+	Wav    *mix.Chunk // nil unless loaded by a MixBackend
+	Volume float32    // from 0 to 1. see Audio
+	path   string
 }
 
+// Play plays the sound in the "sfx" group of AudioShared
 func (w *Wav) Play() (err error) {
-	if w.playing {
+	if AudioShared == nil {
 		return nil
 	}
-	w.playing = true
-	channel, err := w.Wav.Play(0, -1)
-	w.channel = channel
+	_, err = AudioShared.Group("sfx").Play(w, nil)
 	return
 }
 
+// Path returns the file the sound loaded from. Recorded AudioEvents name sounds by it.
+func (w *Wav) Path() string {
+	return w.path
+}
+
+// Stop stops every copy of the sound playing on AudioShared
 func (w *Wav) Stop() {
-	if !w.playing {
+	if AudioShared == nil {
 		return
 	}
-	w.playing = false
-	mix.HaltChannel(w.channel)
+	for i := range AudioShared.channels {
+		if AudioShared.channels[i].wav == w {
+			AudioShared.Stop(i)
+		}
+	}
+}
+
+// Music streams from a file (eg. OGG or MP3) rather than decoding to memory like a Wav.
+// Load it with MusicLoad and play it with Audio.MusicPlay or MusicFade.
+type Music struct {
+	Music  *mix.Music // nil unless loaded by a MixBackend
+	Volume float32    // from 0 to 1. see Audio
+	path   string
+}
+
+// colorLerp returns the color t of the way from a to b, per channel
+func colorLerp(a, b sdl.Color, t float32) sdl.Color {
+	lerp := func(x, y uint8) uint8 { return uint8(float32(x) + (float32(y)-float32(x))*t) }
+	return sdl.Color{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: lerp(a.A, b.A)}
 }
 
 // SDLC converts a uint32 to an sdl.Color
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"frogger/gas"
+	"sync/atomic"
+	"time"
+
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// intro spawns the title sequence into world and calls done when it ends.
+// Run it on the game loop. Clear world before running it again.
+func intro(world *gas.Dob, cam *gas.Camera, titleFont, creditFont *ttf.Font, done func()) error {
+	// the spawn order establishs the z rendering order
+	var dobs [6]*gas.Dob
+	for i, path := range []string{"img/bg.png", "img/heart1.png", "img/heart2.png", "img/frog.png", "", ""} {
+		d, err := world.Spawn(path)
+		if err != nil {
+			return err
+		}
+		dobs[i] = d
+	}
+	bg, heart1, heart2, frog, credit, title := dobs[0], dobs[1], dobs[2], dobs[3], dobs[4], dobs[5]
+	heart2.Exit()
+
+	// bg fills the height of the view at any size, safe area or not
+	bg.Anchor(gas.AnchorCenter.Size(0, 1).Bleeds())
+
+	// the intro was laid out on 800x600. pt maps points from that layout onto the stage
+	// at any size, and k scales sizes with the stage height
+	w, h := float32(world.D[0]), float32(world.D[1])
+	pt := func(x, y float32) (float32, float32) { return x / 800 * w, y / 600 * h }
+	k := h / 600
+
+	// title
+	if err := title.TxtFillOut("Frogger", gas.SDLC(0x00ff00ff), titleFont, 4, gas.SDLC(0x333333ff)); err != nil {
+		return err
+	}
+	title.Scale = .7 * k
+	titleX, titleY := pt(400, 300)
+	title.
+		Move(pt(800, 300)).
+		MoveTo(titleX, titleY, 2*time.Second, gas.EaseInOutSin).
+		Then(func(d *gas.Dob) {
+			// stays centered if the window resizes
+			d.Anchor(gas.AnchorCenter)
+		})
+
+	// frog
+	// Note use of Promise here that reduces nesting of anim code
+	// but costs some extra boilerplate and an atomic lock
+	frog.Scale = .05 * k
+	frogX, frogY := pt(120, 300)
+	frog.
+		Move(pt(0, 200)).
+		MoveTo(frogX, frogY, 2*time.Second, gas.EaseInOutSin).
+		Promise(func(d *gas.Dob, lock *atomic.Bool) {
+			// move and zoom
+			x, y := pt(300, 120)
+			d.MoveTo(x, y, 2*time.Second, nil)
+			d.ZoomTo(4, 2*time.Second, nil).Resolve(lock)
+		}).
+		Then(func(d *gas.Dob) {
+			// move and zoom again. note how these race to Exit
+			x, y := pt(330, 280)
+			d.ZoomTo(.25, 3*time.Second, gas.EaseInOutSin).Exit()
+			d.MoveTo(x, y, 3*time.Second, nil)
+		})
+
+	// credit, pinned two thirds across and down the safe area
+	if err := credit.TxtFillOut("©2023 jkassis", gas.SDLC(0xffff33dd), creditFont, 2, gas.SDLC(0x003300dd)); err != nil {
+		return err
+	}
+	credit.Scale = k
+	credit.Zoom(.01)
+	credit.Anchor(gas.Anchor{X: 2. / 3, Y: 2. / 3})
+
+	// hearts
+	// Note use of Then here which increases nesting of anim code
+	// but requires less boilerplate and performs better.
+	heart1.Scale = .1 * k
+	heart2.Scale = .1 * k
+	heartX, heartY := pt(120, 300)
+	heart1.
+		Move(pt(0, 200)).
+		MoveTo(heartX, heartY, 2*time.Second, gas.EaseInOutSin).
+		MoveTo(credit.Px, credit.Py, 3*time.Second, gas.EaseInOutSin).
+		Then(func(d *gas.Dob) {
+			credit.
+				ZoomTo(1, 3*time.Second, gas.EaseInOutSin).Then(func(d *gas.Dob) {
+				// note how we trigger this title anim when the logo anim completes
+				title.
+					ZoomTo(2, 200*time.Millisecond, nil).
+					Then(func(d *gas.Dob) {
+						cam.Shake(12*k, 400*time.Millisecond)
+					}).
+					ZoomTo(1, 400*time.Millisecond, nil).
+					Then(func(d *gas.Dob) {
+						done()
+					})
+			})
+		})
+
+	// heart particles trail heart1 and burst across the screen
+	heartsEmit := func(path string, rate float32) {
+		hearts, err := heart1.Emitter(path, gas.EmitCfg{
+			Duration: 3 * time.Second,
+			Rate:     rate,
+			Max:      500,
+			Life:     4 * time.Second,
+			LifeVar:  2 * time.Second,
+			Speed:    120,
+			SpeedVar: 100,
+			DirVar:   180,
+			Drag:     .2,
+			SpinVar:  45,
+			Scale:    gas.Curve{1, 1, .5},
+			Color:    gas.ColorCurve{gas.SDLC(0xffffffff), gas.SDLC(0xffffffff), gas.SDLC(0xffffff00)},
+		})
+		if err != nil {
+			world.Stage.Log.Error("emitter", "path", path, "err", err)
+			return
+		}
+		hearts.Dob().
+			Move(pt(0, 200)).
+			MoveTo(heartX, heartY, 2*time.Second, gas.EaseInOutSin).
+			MoveTo(credit.Px, credit.Py, 3*time.Second, gas.EaseInOutSin)
+		hearts.Exit()
+	}
+	heartsEmit("img/heart1.png", 30)
+	heartsEmit("img/heart3.png", 10)
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"frogger/gas"
+	"os"
+	"testing"
+	"time"
+)
+
+// BenchmarkIntro plays the intro on a pooled stage, replaying it as soon as it ends.
+// Reports time and allocs per frame once the pools and particles warm up.
+//
+//	SDL_VIDEODRIVER=dummy go test -tags static -run - -bench Intro .
+func BenchmarkIntro(b *testing.B) {
+	if os.Getenv("SDL_VIDEODRIVER") == "" {
+		os.Setenv("SDL_VIDEODRIVER", "dummy")
+	}
+	os.Setenv("SDL_AUDIODRIVER", "dummy")
+	if err := gas.Init(); err != nil {
+		b.Skip("no sdl:", err)
+	}
+	defer gas.Destroy()
+	v, err := gas.MakeView(800, 600, "bench")
+	if err != nil {
+		b.Skip("no view:", err)
+	}
+	defer v.Destroy()
+	s, err := gas.MakeStage(v)
+	if err != nil {
+		b.Fatal(err)
+	}
+	s.Pool = gas.MakePool()
+	cam := s.CameraAdd(nil)
+	world := s.LayerAdd("world", 0, 1)
+	bangers128, err := v.FontLoad("fonts/Bangers-Regular.ttf", 128)
+	if err != nil {
+		b.Fatal(err)
+	}
+	concertOne48, err := v.FontLoad("fonts/ConcertOne-Regular.ttf", 48)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	director := gas.MakeDirector(v)
+	director.DurationPerTick = int64(time.Second / 30)
+	director.Push(s, nil)
+	var replay func()
+	replay = func() {
+		if err := intro(&world.Dob, cam, bangers128, concertOne48, func() {
+			director.Do(func() {
+				world.Clear()
+				replay()
+			})
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	replay()
+
+	// fill the pools and the particle buffers over a couple of replays
+	for i := 0; i < 600; i++ {
+		director.Frame()
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		director.Frame()
+	}
+}
@@ -5,44 +5,18 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"frogger/gas"
+	"frogger/gas/ui"
 	"math/rand"
 	"os"
 	"runtime"
-	"sync/atomic"
 	"time"
 
-	"cloud.google.com/go/profiler"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
-func init() {
-	// StackDriver Profiling
-	googleProfilerEnabled := os.Getenv("GOOGLE_PROFILER_ENABLED")
-	if googleProfilerEnabled != "TRUE" {
-		fmt.Printf("Profiling disabled.")
-	} else {
-		googleProjectID := os.Getenv("GOOGLE_PROFILER_PROJECT_ID")
-		if googleProjectID == "" {
-			fmt.Printf("Did not find a googleProjectID. Profiling disabled.")
-		} else {
-			fmt.Printf("Profiling enabled.")
-			if err := profiler.Start(profiler.Config{
-				Service:              "gameanimationsystem",
-				NoHeapProfiling:      false,
-				NoAllocProfiling:     false,
-				MutexProfiling:       true,
-				NoGoroutineProfiling: false,
-				DebugLogging:         true,
-				ProjectID:            googleProjectID,
-			}); err != nil {
-				fmt.Println(err)
-			}
-		}
-	}
-}
-
 func CHECK(err error) {
 	if err != nil {
 		fmt.Fprint(os.Stderr, err.Error())
@@ -51,6 +25,13 @@ func CHECK(err error) {
 }
 
 func main() {
+	record := flag.String("record", "", "record frames to a .gif, or to a png sequence named with a frame number pattern like shots/frame-%05d.png")
+	frames := flag.Int("frames", 0, "quit after recording this many frames. 0 records until quit")
+	inspect := flag.String("inspect", "", "serve the dob tree, stats and commands over http at this localhost address, eg. localhost:6061")
+	debug := flag.Bool("debug", false, "start with the debug overlay. f3 toggles it, f4 toggles bounding boxes")
+	prof := profileFlags()
+	flag.Parse()
+
 	runtime.LockOSThread()
 
 	rand.Seed(time.Now().UnixNano())
@@ -58,129 +39,182 @@ func main() {
 	CHECK(gas.Init())
 	defer gas.Destroy()
 
-	v, err := gas.MakeView(800, 600, "Frogger")
-	CHECK(err)
+	// the stage follows the window size. anchors and layouts keep the scene in place
+	v := &gas.View{W: 800, H: 600, Title: "Frogger", Resizable: true, HighDPI: true}
+	CHECK(v.Init())
 	defer v.Destroy()
 
 	s, err := gas.MakeStage(v)
 	CHECK(err)
 	s.BGColor = sdl.Color{R: 0x01, G: 0xb3, B: 0x35, A: 0xff}
+	s.Pool = gas.MakePool()
+	cam := s.CameraAdd(nil)
 
 	bangers128, err := v.FontLoad("fonts/Bangers-Regular.ttf", 128)
 	CHECK(err)
 	concertOne48, err := v.FontLoad("fonts/ConcertOne-Regular.ttf", 48)
 	CHECK(err)
+	concertOne16, err := v.FontLoad("fonts/ConcertOne-Regular.ttf", 16)
+	CHECK(err)
 
-	intro := func() chan struct{} {
-		defer func() {
-			err := recover()
-			if err != nil {
-				fmt.Printf("panic: %v\n", err)
-			}
-		}()
-
-		done := make(chan struct{})
-
-		// the spawn order establishs the z rendering order
-		bg, _ := s.Root.Spawn("img/bg.png")
-		heart1, _ := s.Root.Spawn("img/heart1.png")
-		heart2, _ := s.Root.Spawn("img/heart2.png")
-		heart2.Exit()
-		heart3, _ := s.Root.Spawn("img/heart3.png")
-		heart3.Exit()
-		frog, _ := s.Root.Spawn("img/frog.png")
-		credit, _ := s.Root.Spawn("")
-		title, _ := s.Root.Spawn("")
-
-		// bg
-		bg.Move(400, 300)
-
-		// title
-		title.TxtFillOut("Frogger", gas.SDLC(0x00ff00ff), bangers128, 4, gas.SDLC(0x333333ff))
-		title.Scale = .7
-		title.
-			Move(800, 300).
-			MoveTo(400, 300, 2*time.Second, gas.EaseInOutSin)
-
-		// frog
-		// Note use of Promise here that reduces nesting of anim code
-		// but costs some extra boilerplate and an atomic lock
-		frog.Scale = .05
-		frog.
-			Move(0, 200).
-			MoveTo(120, 300, 2*time.Second, gas.EaseInOutSin).
-			Promise(func(d *gas.Dob, lock *atomic.Bool) {
-				// move and zoom
-				d.MoveTo(300, 120, 2*time.Second, nil)
-				d.ZoomTo(4, 2*time.Second, nil).Resolve(lock)
-			}).
-			Then(func(d *gas.Dob) {
-				// move and zoom again. note how these race to Exit
-				d.ZoomTo(.25, 3*time.Second, gas.EaseInOutSin).Exit()
-				d.MoveTo(330, 280, 3*time.Second, nil)
-			})
-
-		// credit
-		credit.TxtFillOut("©2023 jkassis", gas.SDLC(0xffff33dd), concertOne48, 2, gas.SDLC(0x003300dd))
-		credit.Zoom(.01)
-		credit.Move(533, 400)
-
-		// hearts
-		// Note use of Then here which increases nesting of anim code
-		// but requires less boilerplate and performs better.
-		heart1.Scale = .1
-		heart2.Scale = .1
-		heart3.Scale = .2
-		heart1.
-			Move(0, 200).
-			MoveTo(120, 300, 2*time.Second, gas.EaseInOutSin).
-			MoveTo(533, 400, 3*time.Second, gas.EaseInOutSin).
-			Then(func(d *gas.Dob) {
-				credit.
-					ZoomTo(1, 3*time.Second, gas.EaseInOutSin).Then(func(d *gas.Dob) {
-					// note how we trigger this title anim when the logo anim completes
-					title.
-						ZoomTo(2, 200*time.Millisecond, nil).
-						ZoomTo(1, 400*time.Millisecond, nil).
-						Then(func(d *gas.Dob) {
-							close(done)
-						})
-				})
-			})
+	// asdw, arrows or the d-pad hop the player frog
+	in := s.Input
+	in.Bind("up", gas.Key(sdl.SCANCODE_W), gas.Key(sdl.SCANCODE_UP), gas.PadUp, gas.PadStickUp)
+	in.Bind("left", gas.Key(sdl.SCANCODE_A), gas.Key(sdl.SCANCODE_LEFT), gas.PadLeft, gas.PadStickLeft)
+	in.Bind("down", gas.Key(sdl.SCANCODE_S), gas.Key(sdl.SCANCODE_DOWN), gas.PadDown, gas.PadStickDown)
+	in.Bind("right", gas.Key(sdl.SCANCODE_D), gas.Key(sdl.SCANCODE_RIGHT), gas.PadRight, gas.PadStickRight)
+	// the intro plays in the world layer and the frog hops in the layer above it.
+	// Root does not pause, so anything the menu freezes lives in a layer
+	world := s.LayerAdd("world", 0, 1)
+	actors := s.LayerAdd("player", 1, 1)
+	player, err := actors.Spawn("img/frog.png")
+	CHECK(err)
+	player.Scale = .05
+	playerX, playerY := float32(400), float32(560)
+	player.Move(playerX, playerY)
+	hops := map[string][2]float32{"up": {0, -40}, "left": {-40, 0}, "down": {0, 40}, "right": {40, 0}}
+
+	// clicking the frog itself (not the transparent corners of its image) makes it croak with a wiggle on its feet
+	player.HitAlpha = 0x80
+	player.Pivot = [2]float32{0, .5}
+	player.OnPointerDown = func(e *gas.PointerEvent) {
+		player.SpinTo(15, 80*time.Millisecond, nil).SpinTo(-15, 160*time.Millisecond, nil).SpinTo(0, 80*time.Millisecond, nil)
+		e.Cancel()
+	}
 
-		heart1.Emit(heart1, 20, 500*time.Millisecond, 3*time.Second, nil, gas.EaseInOutSinInv,
-			func(d *gas.Dob) {
-				if rand.Intn(100) < 25 {
-					d.Texture = heart3.Texture
-				}
-				spinDst := -90 + 180*rand.Float64()
-				spinDuration := time.Second + gas.RandDuration(4*time.Second)
-				d.SpinTo(spinDst, spinDuration, nil)
-
-				x := float32(v.W) * rand.Float32()
-				y := float32(v.H) * rand.Float32()
-				moveDur := 2*time.Second + gas.RandDuration(4*time.Second)
-				d.MoveTo(x, y, moveDur, nil).Exit()
-			})
+	// the director runs the intro and leaderboard scenes
+	director := gas.MakeDirector(v)
 
-		return done
+	// leaderboard scene
+	lb, err := gas.MakeStage(v)
+	CHECK(err)
+	lb.BGColor = sdl.Color{R: 0x11, G: 0x33, B: 0x11, A: 0xff}
+	lbUI := ui.MakeUI(lb, concertOne48)
+	board := lb.Root.SpawnRect()
+	board.FillC = gas.SDLC(0x00000000)
+	board.Layout = gas.VStack{Align: .5, Gap: 8}
+	board.Anchor(gas.AnchorTop.Off(0, 32))
+	lbUI.Label(board, "LEADERBOARD")
+	board.Relayout()
+	lbNav := lbUI.VerticalList(lb.Root)
+	lbNav.Add("BACK", func(b *ui.Button) { director.Pop(gas.Slide(1, 0, 500*time.Millisecond)) })
+	lbNav.Anchor(gas.AnchorBottom.Off(0, -32))
+	lbNav.FocusAt(0)
+	lbUI.FocusPush(lbNav)
+
+	// escape opens the menu. the menu and dialogs take the keys while open and
+	// pause the world and the frog. the hud keeps animating
+	hud := s.LayerAdd("hud", 2, 0)
+	pause := func(on bool) { world.Paused, actors.Paused = on, on }
+	resume := func(m *ui.Modal) { m.Close(); pause(false) }
+	u := ui.MakeUI(s, concertOne48)
+	player1 := u.Label(&hud.Dob, "")
+	player1.Anchor(gas.AnchorTopLeft.Off(16, 8))
+	initials := func() {
+		m := u.Modal(&hud.Dob, "ENTER INITIALS")
+		field := m.TextInput(3, ui.Upper)
+		ok := func() {
+			player1.Set("PLAYER " + field.Text())
+			player1.Relayout()
+			lbUI.Label(board, field.Text())
+			board.Relayout()
+			resume(m)
+		}
+		field.OnEnter = func(t *ui.TextInput) { ok() }
+		m.Add("OK", func(b *ui.Button) { ok() })
+		m.OnCancel = resume
+	}
+	menu := func() {
+		pause(true)
+		m := u.Modal(&hud.Dob, "FROGGER")
+		m.Add("PLAY", func(b *ui.Button) { resume(m) })
+		m.Add("INITIALS", func(b *ui.Button) { m.Close(); initials() })
+		m.Add("LEADERBOARD", func(b *ui.Button) { resume(m); director.Push(lb, gas.Slide(-1, 0, 500*time.Millisecond)) })
+		m.Add("QUIT", func(b *ui.Button) { sdl.PushEvent(&sdl.QuitEvent{Type: sdl.QUIT}) })
+		m.OnCancel = resume
 	}
 
-	go func() {
-		defer func() {
-			err := recover()
-			if err != nil {
-				fmt.Printf("panic: %v\n", err)
+	// f3 toggles the debug overlay and f4 its bounding boxes
+	dbg := s.DebugOn(concertOne16)
+	dbg.Visible = *debug
+	in.Bind("debug", gas.Key(sdl.SCANCODE_F3))
+	in.Bind("boxes", gas.Key(sdl.SCANCODE_F4))
+
+	// f11 toggles borderless fullscreen. f12 saves a screenshot
+	in.Bind("fullscreen", gas.Key(sdl.SCANCODE_F11))
+	in.Bind("screenshot", gas.Key(sdl.SCANCODE_F12))
+	in.On(func(e gas.InputEvent) {
+		if e.Down && in.Bound("debug", e.Button) {
+			dbg.Toggle()
+			return
+		}
+		if e.Down && in.Bound("boxes", e.Button) {
+			dbg.Boxes = !dbg.Boxes
+			return
+		}
+		if e.Down && in.Bound("fullscreen", e.Button) {
+			if v.Mode == gas.WindowNormal {
+				v.ModeSet(gas.WindowBorderless)
+			} else {
+				v.ModeSet(gas.WindowNormal)
 			}
-		}()
-
-		for {
-			fmt.Println("looping...")
-			<-intro()
-			time.Sleep(time.Second)
-			s.Root.Clear()
+			return
+		}
+		if e.Down && in.Bound("screenshot", e.Button) {
+			s.Screenshot(fmt.Sprintf("../screens/frogger.%d.png", time.Now().Unix()))
+			return
+		}
+		if !e.Down || u.Focused() != nil {
+			return
+		}
+		if in.Bound(ui.ActionCancel, e.Button) {
+			menu()
+			return
 		}
-	}()
+		for action, hop := range hops {
+			if in.Bound(action, e.Button) {
+				playerX, playerY = playerX+hop[0], playerY+hop[1]
+				// the frog faces left. flip it to face right
+				if hop[0] < 0 {
+					player.Flip = sdl.FLIP_NONE
+				} else if hop[0] > 0 {
+					player.Flip = sdl.FLIP_HORIZONTAL
+				}
+				player.MoveTo(playerX, playerY, 150*time.Millisecond, gas.EaseOutSin)
+			}
+		}
+	})
+
+	// the intro replays a second after it ends. the dob tree is not safe for concurrent use,
+	// so the timer asks the director to clear and restart it on the game loop
+	var replay func()
+	replay = func() {
+		s.Log.Debug("intro looping")
+		CHECK(intro(&world.Dob, cam, bangers128, concertOne48, func() {
+			time.AfterFunc(time.Second, func() {
+				director.Do(func() {
+					world.Clear()
+					replay()
+				})
+			})
+		}))
+	}
+	replay()
 
-	s.Play(30)
+	if *record != "" {
+		rec, err := director.Record(*record)
+		CHECK(err)
+		rec.Max = *frames
+	}
+	if *inspect != "" {
+		inspector, err := director.Inspect(*inspect)
+		CHECK(err)
+		defer inspector.Close()
+		s.Log.Info("inspecting", "url", "http://"+inspector.Addr())
+	}
+	CHECK(prof.start(director, s.Log))
+	director.Push(s, nil)
+	director.Play(30)
+	CHECK(prof.stop())
+	CHECK(director.RecordStop())
 }
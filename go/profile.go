@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"frogger/gas"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"syscall"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// profile holds the profiling flags. Everything is off unless a flag is set.
+type profile struct {
+	cpu        *string
+	frameTimes *string
+	mem        *string
+	pprof      *string
+	trace      *string
+	stops      []func() error // run by stop, last first
+}
+
+// profileFlags defines the profiling flags. Call before flag.Parse
+func profileFlags() *profile {
+	return &profile{
+		cpu:        flag.String("cpuprofile", "", "write a cpu profile to this file"),
+		frameTimes: flag.String("frametimes", "", "write histograms of tick, paint, present and frame times to this file"),
+		mem:        flag.String("memprofile", "", "write a heap profile to this file on quit"),
+		pprof:      flag.String("pprof", "", "serve net/http/pprof at this localhost address, eg. localhost:6060"),
+		trace:      flag.String("trace", "", "write an execution trace to this file. tick, paint and present are trace regions"),
+	}
+}
+
+// start starts the profiles the flags ask for
+func (p *profile) start(director *gas.Director, log gas.Logger) error {
+	if *p.pprof != "" {
+		host, _, err := net.SplitHostPort(*p.pprof)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("pprof must listen on localhost, not %s", host)
+		}
+		l, err := net.Listen("tcp", *p.pprof)
+		if err != nil {
+			return err
+		}
+		go http.Serve(l, http.DefaultServeMux)
+		p.stops = append(p.stops, l.Close)
+		log.Info("pprof", "url", "http://"+l.Addr().String()+"/debug/pprof/")
+	}
+	if *p.cpu != "" {
+		f, err := os.Create(*p.cpu)
+		if err != nil {
+			return err
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return err
+		}
+		p.stops = append(p.stops, func() error {
+			pprof.StopCPUProfile()
+			return f.Close()
+		})
+	}
+	if *p.trace != "" {
+		f, err := os.Create(*p.trace)
+		if err != nil {
+			return err
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			return err
+		}
+		p.stops = append(p.stops, func() error {
+			trace.Stop()
+			return f.Close()
+		})
+	}
+	if *p.frameTimes != "" {
+		times := director.TimesOn()
+		p.stops = append(p.stops, func() error {
+			f, err := os.Create(*p.frameTimes)
+			if err != nil {
+				return err
+			}
+			if _, err := times.WriteTo(f); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		})
+	}
+	if *p.mem != "" {
+		p.stops = append(p.stops, func() error {
+			f, err := os.Create(*p.mem)
+			if err != nil {
+				return err
+			}
+			// collect first so the profile shows live memory
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		})
+	}
+	if len(p.stops) > 0 {
+		// quit cleanly on ctrl-c so the files get written
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sig
+			sdl.PushEvent(&sdl.QuitEvent{Type: sdl.QUIT})
+		}()
+	}
+	return nil
+}
+
+// stop stops the profiles and writes their files. Returns the first error
+func (p *profile) stop() error {
+	var first error
+	for i := len(p.stops) - 1; i >= 0; i-- {
+		if err := p.stops[i](); err != nil && first == nil {
+			first = err
+		}
+	}
+	p.stops = nil
+	return first
+}